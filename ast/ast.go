@@ -3,16 +3,21 @@ package ast
 import (
 	"monkey/token"
 	"bytes"
+	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Node represents a node in the Abstract Syntax Tree.
 // Every node in AST must implement the Node interface by providing:
 // - TokenLiteral(): returns the literal value of the token it's associated with
 // - String(): returns a string representation of the node for debugging
+// - Pos(): returns the source position of the token it's associated with
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position
 }
 
 // Statement represents a statement node in the AST.
@@ -46,6 +51,15 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+// Pos returns the position of the first statement.
+// If there are no statements, it returns the zero Position.
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
 // String returns a string representation of the program by concatenating
 // the string representation of all its statements.
 func (p *Program) String() string {
@@ -69,6 +83,7 @@ type LetStatement struct {
 
 func (ls *LetStatement) statementNode() {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position { return ls.Token.Span.Start }
 
 // String returns a string representation of the let statement in the format:
 // "let <identifier> = <expression>;"
@@ -95,6 +110,7 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode() {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position { return rs.Token.Span.Start }
 
 // String returns a string representation of the return statement in the format:
 // "return <expression>;"
@@ -108,6 +124,93 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// WhileStatement represents a loop that runs its body while a condition is truthy.
+// It contains:
+// - Token: the 'while' token
+// - Condition: the expression checked before each iteration
+// - Body: the block to execute
+type WhileStatement struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() token.Position { return ws.Token.Span.Start }
+
+// String returns a string representation of the while statement in the format:
+// "while (<condition>) { <body> }"
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("while (")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(") { ")
+	out.WriteString(ws.Body.String())
+	out.WriteString(" }")
+	return out.String()
+}
+
+// ForStatement represents a loop over the elements of an iterable.
+// It contains:
+// - Token: the 'for' token
+// - Variable: the identifier bound to each element in turn
+// - Iterable: the expression producing the array, string or hash to walk
+// - Body: the block to execute
+type ForStatement struct {
+	Token    token.Token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Position { return fs.Token.Span.Start }
+
+// String returns a string representation of the for statement in the format:
+// "for (<variable> in <iterable>) { <body> }"
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") { ")
+	out.WriteString(fs.Body.String())
+	out.WriteString(" }")
+	return out.String()
+}
+
+// BreakStatement represents a 'break', which ends the innermost loop.
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode() {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Position { return bs.Token.Span.Start }
+
+// String returns "break;".
+func (bs *BreakStatement) String() string {
+	return bs.TokenLiteral() + ";"
+}
+
+// ContinueStatement represents a 'continue', which skips to the next
+// iteration of the innermost loop.
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Position { return cs.Token.Span.Start }
+
+// String returns "continue;".
+func (cs *ContinueStatement) String() string {
+	return cs.TokenLiteral() + ";"
+}
+
 // ExpressionStatement represents a statement that consists of a single expression.
 // It contains:
 // - Token: the first token of the expression
@@ -119,6 +222,7 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode() {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position { return es.Token.Span.Start }
 
 // String returns a string representation of the expression statement.
 // If the expression is nil, returns an empty string.
@@ -140,6 +244,7 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode() {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position { return i.Token.Span.Start }
 
 // String returns the identifier's value as a string.
 func (i *Identifier) String() string {
@@ -157,12 +262,80 @@ type IntegerLiteral struct {
 
 func (il *IntegerLiteral) expressionNode() {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position { return il.Token.Span.Start }
 
 // String returns the integer literal's value as a string.
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+// FloatLiteral represents a floating-point literal such as 0.15 or 1e-3.
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position { return fl.Token.Span.Start }
+
+// String returns the float literal as written in the source.
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+// StringLiteral represents a string literal expression.
+// It contains:
+// - Token: the string token
+// - Value: the string value
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode() {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position { return sl.Token.Span.Start }
+
+// String returns the string literal in double quotes, escaped so that the
+// lexer reads it back as the same value.
+func (sl *StringLiteral) String() string {
+	return quote(sl.Value)
+}
+
+// quote writes s as a Monkey string literal, using the escapes the lexer
+// understands: \n \t \r \0 \\ \" and \u{X} for other non-printable runes.
+func quote(s string) string {
+	var out strings.Builder
+	out.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// not UTF-8; the lexer reads the byte back as is
+			out.WriteByte(s[i])
+		case r == '"' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == 0:
+			out.WriteString(`\0`)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&out, `\u{%X}`, r)
+		default:
+			out.WriteRune(r)
+		}
+		i += size
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
 // PrefixExpression represents a prefix operator expression (e.g., !true, -5).
 // It contains:
 // - Token: the prefix operator token
@@ -176,6 +349,7 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode() {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position { return pe.Token.Span.Start }
 
 // String returns a string representation of the prefix expression in the format:
 // "(<operator><right>)"
@@ -203,6 +377,7 @@ type InfixExpression struct {
 
 func (ie *InfixExpression) expressionNode() {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Pos() token.Position { return ie.Token.Span.Start }
 
 // String returns a string representation of the infix expression in the format:
 // "(<left> <operator> <right>)"
@@ -227,6 +402,7 @@ type Boolean struct {
 
 func (b *Boolean) expressionNode() {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position { return b.Token.Span.Start }
 
 // String returns the boolean literal's value as a string.
 func (b *Boolean) String() string {
@@ -248,6 +424,7 @@ type IfExpression struct {
 
 func (ie *IfExpression) expressionNode() {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position { return ie.Token.Span.Start }
 
 // String returns a string representation of the if expression in the format:
 // "if <condition> { <consequence> } else { <alternative> }"
@@ -275,6 +452,7 @@ type BlockStatement struct {
 
 func (bs *BlockStatement) statementNode() {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position { return bs.Token.Span.Start }
 
 // String returns a string representation of the block statement by concatenating
 // the string representation of all its statements.
@@ -295,10 +473,12 @@ type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Name       string // set by the parser for `let name = fn...`, "" otherwise
 }
 
 func (fl *FunctionLiteral) expressionNode() {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position { return fl.Token.Span.Start }
 
 // String returns a string representation of the function literal in the format:
 // "fn(<param1>, <param2>, ...) { <body> }"
@@ -329,6 +509,7 @@ type CallExpression struct {
 
 func (ce *CallExpression) expressionNode() {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position { return ce.Token.Span.Start }
 
 // String returns a string representation of the function call in the format:
 // "<function>(<arg1>, <arg2>, ...)"
@@ -345,4 +526,94 @@ func (ce *CallExpression) String() string {
 	out.WriteString(")")
 
 	return out.String()
-}
\ No newline at end of file
+}
+// ArrayLiteral represents an array literal expression (e.g., [1, 2 * 2, fn(x) { x }]).
+// It contains:
+// - Token: the '[' token
+// - Elements: list of element expressions
+type ArrayLiteral struct {
+	Token    token.Token // The '[' token
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode() {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position { return al.Token.Span.Start }
+
+// String returns a string representation of the array literal in the format:
+// "[<element1>, <element2>, ...]"
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// IndexExpression represents an index operator expression (e.g., myArray[1]).
+// It contains:
+// - Token: the '[' token
+// - Left: the expression being indexed
+// - Index: the index expression
+type IndexExpression struct {
+	Token token.Token // The '[' token
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode() {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position { return ie.Token.Span.Start }
+
+// String returns a string representation of the index expression in the format:
+// "(<left>[<index>])"
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+	return out.String()
+}
+
+// HashPair is a single "<key>: <value>" entry of a hash literal.
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+// HashLiteral represents a hash literal expression (e.g., {"name": "x", 1: true}).
+// It contains:
+// - Token: the '{' token
+// - Pairs: the key/value pairs in source order
+type HashLiteral struct {
+	Token token.Token // The '{' token
+	Pairs []HashPair
+}
+
+func (hl *HashLiteral) expressionNode() {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position { return hl.Token.Span.Start }
+
+// String returns a string representation of the hash literal in the format:
+// "{<key1>: <value1>, <key2>: <value2>, ...}"
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"bytes"
 	"monkey/token"
 	"testing"
 )
@@ -26,3 +27,48 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestFprint(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let", Span: token.Span{Start: token.Position{Line: 1, Column: 1}}},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x"},
+					Value: "x",
+				},
+				Value: &CallExpression{
+					Token:    token.Token{Type: token.LPAREN, Literal: "("},
+					Function: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "f"}, Value: "f"},
+					Arguments: []Expression{
+						&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+					},
+				},
+			},
+			&ExpressionStatement{
+				Token: token.Token{Type: token.LBRACKET, Literal: "["},
+				Expression: &ArrayLiteral{
+					Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+					Elements: []Expression{},
+				},
+			},
+		},
+	}
+
+	expected := `Program
+  LetStatement "let" @1:1
+    Name: Identifier "x"
+    Value: CallExpression "("
+      Function: Identifier "f"
+      Arguments[0]: IntegerLiteral "1"
+  ExpressionStatement "["
+    Expression: ArrayLiteral "["
+      Elements: []
+`
+
+	var out bytes.Buffer
+	Fprint(&out, program)
+	if out.String() != expected {
+		t.Errorf("Fprint output wrong.\nexpected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
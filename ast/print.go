@@ -0,0 +1,97 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Fprint writes the tree rooted at node to w, one node per line with
+// children indented below their parent, e.g. for "let x = 1 + 2;":
+//
+//	Program
+//	  LetStatement "let" @1:1
+//	    Name: Identifier "x" @1:5
+//	    Value: InfixExpression "+" @1:11
+//	      Left: IntegerLiteral "1" @1:9
+//	      Right: IntegerLiteral "2" @1:13
+//
+// It walks the node structs with reflection, so new node types are
+// printed without changes here.
+func Fprint(w io.Writer, node Node) {
+	printNode(w, "", reflect.ValueOf(node), 0)
+}
+
+var nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+
+func printNode(w io.Writer, label string, v reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if label != "" {
+		label += ": "
+	}
+
+	if !v.IsValid() || (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) && v.IsNil() {
+		fmt.Fprintf(w, "%s%snil\n", indent, label)
+		return
+	}
+	node, ok := v.Interface().(Node)
+	if !ok {
+		fmt.Fprintf(w, "%s%s%v\n", indent, label, v.Interface())
+		return
+	}
+
+	elem := reflect.Indirect(reflect.ValueOf(node))
+	header := elem.Type().Name()
+	if _, isProgram := node.(*Program); !isProgram {
+		header += fmt.Sprintf(" %q", node.TokenLiteral())
+		if pos := node.Pos(); pos.IsValid() {
+			header += " @" + pos.String()
+		}
+	}
+	fmt.Fprintf(w, "%s%s%s\n", indent, label, header)
+
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		value := elem.Field(i)
+		if field.Name == "Token" || !field.IsExported() {
+			continue
+		}
+		printField(w, field.Name, value, depth+1, field.Name == "Statements")
+	}
+}
+
+// printField prints a child of a node. Statement lists are printed without a
+// label to keep block bodies compact; other slices list their elements with
+// an index. Plain values (operators, literal values) are skipped since they
+// are already shown by the node's token literal.
+func printField(w io.Writer, name string, v reflect.Value, depth int, inline bool) {
+	switch {
+	case v.Kind() == reflect.Slice:
+		if inline {
+			for i := 0; i < v.Len(); i++ {
+				printNode(w, "", v.Index(i), depth)
+			}
+			return
+		}
+		if v.Len() == 0 {
+			fmt.Fprintf(w, "%s%s: []\n", strings.Repeat("  ", depth), name)
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			el := v.Index(i)
+			label := fmt.Sprintf("%s[%d]", name, i)
+			if el.Kind() == reflect.Struct {
+				// composite entries such as HashPair
+				fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), label)
+				for j := 0; j < el.NumField(); j++ {
+					printField(w, el.Type().Field(j).Name, el.Field(j), depth+1, false)
+				}
+				continue
+			}
+			printNode(w, label, el, depth)
+		}
+	case v.Type().Implements(nodeType):
+		printNode(w, name, v, depth)
+	}
+}
@@ -0,0 +1,141 @@
+// Package diagnostic describes problems found in Monkey source code
+// and renders them against the source text for humans.
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"monkey/token"
+	"strings"
+)
+
+// Severity tells how serious a diagnostic is.
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Note:
+		return "note"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Diagnostic is a single problem reported about the source.
+// It contains:
+// - Severity: how serious the problem is
+// - Code: a stable identifier tools can match on (e.g. "P0001")
+// - Message: the human readable description
+// - Span: the source range the problem refers to
+// - Hints: optional suggestions on how to fix it
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Span     token.Span
+	Hints    []string
+}
+
+// String returns the diagnostic on one line in the format:
+// "<position>: <severity>[<code>]: <message>"
+func (d Diagnostic) String() string {
+	var out strings.Builder
+	if d.Span.Start.IsValid() {
+		out.WriteString(d.Span.Start.String() + ": ")
+	}
+	out.WriteString(d.Severity.String())
+	if d.Code != "" {
+		out.WriteString("[" + d.Code + "]")
+	}
+	out.WriteString(": " + d.Message)
+	return out.String()
+}
+
+// Error makes a Diagnostic usable as a Go error.
+func (d Diagnostic) Error() string { return d.String() }
+
+// Render writes every diagnostic followed by the offending source line
+// with a caret underline, e.g.:
+//
+//	error[P0001]: expected next token to be IDENT, got = instead
+//	 --> 2:5
+//	  |
+//	2 | let = 10;
+//	  |     ^
+func Render(w io.Writer, source string, diags []Diagnostic) {
+	lines := splitLines(source)
+	for _, d := range diags {
+		renderOne(w, lines, d)
+	}
+}
+
+// splitLines splits source at line breaks the way the lexer counts them:
+// "\n", "\r\n" and a lone "\r" each end a line.
+func splitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	return strings.Split(source, "\n")
+}
+
+func renderOne(w io.Writer, lines []string, d Diagnostic) {
+	header := d.Severity.String()
+	if d.Code != "" {
+		header += "[" + d.Code + "]"
+	}
+	fmt.Fprintf(w, "%s: %s\n", header, d.Message)
+
+	start := d.Span.Start
+	if !start.IsValid() || start.Line > len(lines) {
+		for _, h := range d.Hints {
+			fmt.Fprintf(w, " = hint: %s\n", h)
+		}
+		return
+	}
+
+	lineNo := fmt.Sprintf("%d", start.Line)
+	gutter := strings.Repeat(" ", len(lineNo))
+	src := lines[start.Line-1]
+
+	fmt.Fprintf(w, "%s--> %s\n", gutter, start)
+	fmt.Fprintf(w, "%s |\n", gutter)
+	fmt.Fprintf(w, "%s | %s\n", lineNo, src)
+	fmt.Fprintf(w, "%s | %s\n", gutter, underline(src, start, d.Span.End))
+	for _, h := range d.Hints {
+		fmt.Fprintf(w, "%s = hint: %s\n", gutter, h)
+	}
+}
+
+// underline builds the caret line for the span on src. Tabs before the
+// span are kept so the carets line up with the source above. Spans that
+// continue on later lines are underlined to the end of src.
+func underline(src string, start, end token.Position) string {
+	var out strings.Builder
+	runes := []rune(src)
+	col := 1
+	for ; col < start.Column && col <= len(runes); col++ {
+		if runes[col-1] == '\t' {
+			out.WriteRune('\t')
+		} else {
+			out.WriteRune(' ')
+		}
+	}
+
+	width := 1
+	if end.Line == start.Line && end.Column > start.Column {
+		width = end.Column - start.Column
+	} else if end.Line > start.Line && len(runes) >= start.Column {
+		width = len(runes) - start.Column + 1
+	}
+	out.WriteString(strings.Repeat("^", width))
+	return out.String()
+}
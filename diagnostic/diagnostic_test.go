@@ -0,0 +1,116 @@
+package diagnostic
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/token"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	source := "let x = 5;\n\tlet = 10;"
+	diags := []Diagnostic{
+		{
+			Severity: Error,
+			Code:     "P0001",
+			Message:  "expected next token to be IDENT, got = instead",
+			Span: token.Span{
+				Start: token.Position{Line: 2, Column: 6},
+				End:   token.Position{Line: 2, Column: 7},
+			},
+			Hints: []string{"give the binding a name"},
+		},
+		{
+			Severity: Warning,
+			Message:  "unused binding",
+			Span: token.Span{
+				Start: token.Position{Line: 1, Column: 5},
+				End:   token.Position{Line: 1, Column: 6},
+			},
+		},
+	}
+
+	expected := "error[P0001]: expected next token to be IDENT, got = instead\n" +
+		" --> 2:6\n" +
+		"  |\n" +
+		"2 | \tlet = 10;\n" +
+		"  | \t    ^\n" +
+		"  = hint: give the binding a name\n" +
+		"warning: unused binding\n" +
+		" --> 1:5\n" +
+		"  |\n" +
+		"1 | let x = 5;\n" +
+		"  |     ^\n"
+
+	var out bytes.Buffer
+	Render(&out, source, diags)
+	if out.String() != expected {
+		t.Errorf("Render output wrong.\nexpected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+func TestRenderLineBreaks(t *testing.T) {
+	// the lexer counts "\n", "\r\n" and a lone "\r" as line breaks
+	tests := []struct {
+		source string
+		line   int
+	}{
+		{"let a = 1;\rlet = 2;", 2},
+		{"let a = 1;\r\nlet = 2;", 2},
+		{"let a = 1;\nlet = 2;", 2},
+		{"x;\r\rlet a = 1;\r\nlet = 2;", 4},
+	}
+
+	for _, tt := range tests {
+		diags := []Diagnostic{{
+			Severity: Error,
+			Message:  "bad let",
+			Span: token.Span{
+				Start: token.Position{Line: tt.line, Column: 5},
+				End:   token.Position{Line: tt.line, Column: 6},
+			},
+		}}
+
+		var out bytes.Buffer
+		Render(&out, tt.source, diags)
+		expected := fmt.Sprintf("%d | let = 2;\n  |     ^\n", tt.line)
+		if !strings.HasSuffix(out.String(), expected) {
+			t.Errorf("Render(%q) wrong. got=\n%s", tt.source, out.String())
+		}
+	}
+}
+
+func TestUnderlineWidth(t *testing.T) {
+	tests := []struct {
+		src      string
+		start    token.Position
+		end      token.Position
+		expected string
+	}{
+		{"foo(bar)", token.Position{Line: 1, Column: 5}, token.Position{Line: 1, Column: 8}, "    ^^^"},
+		{"let", token.Position{Line: 1, Column: 4}, token.Position{Line: 1, Column: 4}, "   ^"},
+		{"fn(x) {", token.Position{Line: 1, Column: 7}, token.Position{Line: 3, Column: 2}, "      ^"},
+		{"\"é\" + 1", token.Position{Line: 1, Column: 5}, token.Position{Line: 1, Column: 6}, "    ^"},
+	}
+
+	for i, tt := range tests {
+		got := underline(tt.src, tt.start, tt.end)
+		if got != tt.expected {
+			t.Errorf("tests[%d] - underline wrong. expected=%q, got=%q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	d := Diagnostic{
+		Severity: Error,
+		Code:     "P0002",
+		Message:  "no prefix parse function for ) found",
+		Span:     token.Span{Start: token.Position{Filename: "a.monkey", Line: 3, Column: 1}},
+	}
+	expected := "a.monkey:3:1: error[P0002]: no prefix parse function for ) found"
+	if d.String() != expected {
+		t.Errorf("String() wrong. expected=%q, got=%q", expected, d.String())
+	}
+}
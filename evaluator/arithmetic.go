@@ -0,0 +1,182 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"monkey/object"
+)
+
+// isNumber reports whether obj takes part in arithmetic.
+func isNumber(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Integer, *object.BigInt, *object.Float:
+		return true
+	}
+	return false
+}
+
+// isInteger reports whether obj is an Integer or a BigInt.
+func isInteger(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Integer, *object.BigInt:
+		return true
+	}
+	return false
+}
+
+// toFloat converts a number to float64, promoting integers.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInt:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	case *object.Float:
+		return obj.Value
+	}
+	return math.NaN()
+}
+
+// toBig converts an Integer or BigInt to a *big.Int the caller may modify.
+func toBig(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInt:
+		return new(big.Int).Set(obj.Value)
+	}
+	return new(big.Int)
+}
+
+// evalFloatInfixExpression applies operator to two numbers of which at least
+// one is a float; the integer, if any, is promoted to float first.
+// Division and modulo by zero are errors, as they are for integers.
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %s / %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero: %s %% %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalIntegerArithmetic computes left operator right for + - * / %.
+// Dividing by zero is an error. A result that does not fit in an int64 is
+// computed again as a BigInt, or is an error in checked mode.
+func evalIntegerArithmetic(operator string, left, right int64, checked bool) object.Object {
+	var result int64
+	overflow := false
+
+	switch operator {
+	case "+":
+		result = left + right
+		overflow = (left > 0 && right > 0 && result < 0) || (left < 0 && right < 0 && result >= 0)
+	case "-":
+		result = left - right
+		overflow = (left >= 0 && right < 0 && result < 0) || (left < 0 && right > 0 && result >= 0)
+	case "*":
+		result = left * right
+		overflow = left != 0 && (result/left != right || (left == -1 && right == math.MinInt64))
+	case "/":
+		if right == 0 {
+			return newError("division by zero: %d / 0", left)
+		}
+		result = left / right
+		overflow = left == math.MinInt64 && right == -1
+	case "%":
+		if right == 0 {
+			return newError("modulo by zero: %d %% 0", left)
+		}
+		result = left % right
+	}
+
+	if overflow {
+		if checked {
+			return newError("integer overflow: %d %s %d", left, operator, right)
+		}
+		return evalBigIntArithmetic(operator, big.NewInt(left), big.NewInt(right))
+	}
+	return &object.Integer{Value: result}
+}
+
+// evalBigIntInfixExpression applies operator to two integers of which at
+// least one is a BigInt. Results that fit in an int64 become Integers again.
+func evalBigIntInfixExpression(operator string, left, right object.Object) object.Object {
+	if isBitwiseOperator(operator) {
+		return evalBitwiseExpression(operator, left, right, false)
+	}
+	leftVal := toBig(left)
+	rightVal := toBig(right)
+	switch operator {
+	case "+", "-", "*", "/", "%":
+		return evalBigIntArithmetic(operator, leftVal, rightVal)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalBigIntArithmetic computes left operator right with arbitrary precision.
+// Division truncates and the remainder has the sign of left, as for int64.
+func evalBigIntArithmetic(operator string, left, right *big.Int) object.Object {
+	result := new(big.Int)
+	switch operator {
+	case "+":
+		result.Add(left, right)
+	case "-":
+		result.Sub(left, right)
+	case "*":
+		result.Mul(left, right)
+	case "/":
+		if right.Sign() == 0 {
+			return newError("division by zero: %s / 0", left)
+		}
+		result.Quo(left, right)
+	case "%":
+		if right.Sign() == 0 {
+			return newError("modulo by zero: %s %% 0", left)
+		}
+		result.Rem(left, right)
+	}
+	return object.NewInteger(result)
+}
@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"math/big"
+	"monkey/object"
+)
+
+// maxShiftCount bounds left shifts of non-zero values, which grow the
+// result by one bit per count, so a script cannot exhaust memory.
+const maxShiftCount = 1 << 20
+
+// isBitwiseOperator reports whether operator is one of & | ^ << >>.
+func isBitwiseOperator(operator string) bool {
+	switch operator {
+	case "&", "|", "^", "<<", ">>":
+		return true
+	}
+	return false
+}
+
+// evalBitwiseExpression applies & | ^ << >> to two integers, each an Integer
+// or a BigInt, treating negative numbers as two's complement like Go does.
+// Shift counts must not be negative. A left shift that overflows an int64
+// gives a BigInt, or an error in checked mode.
+func evalBitwiseExpression(operator string, left, right object.Object, checked bool) object.Object {
+	if operator == "<<" || operator == ">>" {
+		if isNegative(right) {
+			return newError("negative shift count: %s %s %s", left.Inspect(), operator, right.Inspect())
+		}
+	}
+
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if lok && rok {
+		return evalIntegerBitwise(operator, l.Value, r.Value, checked)
+	}
+
+	leftVal := toBig(left)
+	rightVal := toBig(right)
+	result := new(big.Int)
+	switch operator {
+	case "&":
+		result.And(leftVal, rightVal)
+	case "|":
+		result.Or(leftVal, rightVal)
+	case "^":
+		result.Xor(leftVal, rightVal)
+	case "<<":
+		if leftVal.Sign() != 0 && (!rightVal.IsInt64() || rightVal.Int64() > maxShiftCount) {
+			return newError("shift count too large: %s << %s", left.Inspect(), right.Inspect())
+		}
+		if leftVal.Sign() != 0 {
+			result.Lsh(leftVal, uint(rightVal.Int64()))
+		}
+	case ">>":
+		if !rightVal.IsInt64() || rightVal.Int64() > maxShiftCount {
+			// every bit is shifted out, leaving only the sign
+			rightVal.SetInt64(int64(leftVal.BitLen()) + 1)
+		}
+		result.Rsh(leftVal, uint(rightVal.Int64()))
+	}
+	return object.NewInteger(result)
+}
+
+func evalIntegerBitwise(operator string, left, right int64, checked bool) object.Object {
+	switch operator {
+	case "&":
+		return &object.Integer{Value: left & right}
+	case "|":
+		return &object.Integer{Value: left | right}
+	case "^":
+		return &object.Integer{Value: left ^ right}
+	case ">>":
+		return &object.Integer{Value: left >> uint64(right)}
+	}
+
+	// "<<": detect bits shifted out, including the sign bit
+	if left == 0 {
+		return &object.Integer{Value: 0}
+	}
+	if right < 63 {
+		if result := left << uint64(right); result>>uint64(right) == left {
+			return &object.Integer{Value: result}
+		}
+	}
+	if checked {
+		return newError("integer overflow: %d << %d", left, right)
+	}
+	return evalBitwiseExpression("<<", &object.BigInt{Value: big.NewInt(left)}, &object.Integer{Value: right}, false)
+}
+
+// evalBitwiseNotExpression evaluates ~x, which is -x - 1 for any integer.
+func evalBitwiseNotExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInt:
+		return object.NewInteger(new(big.Int).Not(right.Value))
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
+}
+
+func isNegative(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return obj.Value < 0
+	case *object.BigInt:
+		return obj.Value.Sign() < 0
+	}
+	return false
+}
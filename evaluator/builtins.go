@@ -0,0 +1,140 @@
+package evaluator
+
+import (
+	"fmt"
+	"io"
+	"monkey/object"
+	"os"
+	"sort"
+	"unicode/utf8"
+)
+
+// putsBuiltin prints to the output of the environment it is called from,
+// which Fn cannot see, so applyFunction calls puts for it directly. Fn
+// prints to os.Stdout for callers that have no environment.
+var putsBuiltin = &object.Builtin{
+	Fn: func(args ...object.Object) object.Object {
+		return puts(os.Stdout, args)
+	},
+}
+
+// puts writes each of args to out on a line of its own.
+func puts(out io.Writer, args []object.Object) object.Object {
+	for _, arg := range args {
+		fmt.Fprintln(out, arg.Inspect())
+	}
+	return NULL
+}
+
+// builtins holds the native functions available to every program.
+// evalIdentifier consults it after the environment, so scripts may shadow them.
+var builtins = map[string]*object.Builtin{
+	"len": {
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("len", args, 1); err != nil {
+				return err
+			}
+
+			switch arg := args[0].(type) {
+			case *object.String:
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Array:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Pairs))}
+			default:
+				return newError("argument to `len` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"puts": putsBuiltin,
+	"first": {
+		Fn: func(args ...object.Object) object.Object {
+			arr, err := arrayArg("first", args, 1)
+			if err != nil {
+				return err
+			}
+			if len(arr.Elements) > 0 {
+				return arr.Elements[0]
+			}
+			return NULL
+		},
+	},
+	"last": {
+		Fn: func(args ...object.Object) object.Object {
+			arr, err := arrayArg("last", args, 1)
+			if err != nil {
+				return err
+			}
+			length := len(arr.Elements)
+			if length > 0 {
+				return arr.Elements[length-1]
+			}
+			return NULL
+		},
+	},
+	"rest": {
+		Fn: func(args ...object.Object) object.Object {
+			arr, err := arrayArg("rest", args, 1)
+			if err != nil {
+				return err
+			}
+			length := len(arr.Elements)
+			if length > 0 {
+				newElements := make([]object.Object, length-1)
+				copy(newElements, arr.Elements[1:length])
+				return &object.Array{Elements: newElements}
+			}
+			return NULL
+		},
+	},
+	"push": {
+		Fn: func(args ...object.Object) object.Object {
+			arr, err := arrayArg("push", args, 2)
+			if err != nil {
+				return err
+			}
+			length := len(arr.Elements)
+			newElements := make([]object.Object, length+1)
+			copy(newElements, arr.Elements)
+			newElements[length] = args[1]
+			return &object.Array{Elements: newElements}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range builtins {
+		builtin.Name = name
+	}
+}
+
+// BuiltinNames returns the names of all builtin functions in sorted order.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkArgCount returns an error object unless exactly want arguments were passed.
+func checkArgCount(name string, args []object.Object, want int) *object.Error {
+	if len(args) != want {
+		return newError("wrong number of arguments to `%s`. got=%d, want=%d", name, len(args), want)
+	}
+	return nil
+}
+
+// arrayArg checks the argument count and that the first argument is an ARRAY.
+func arrayArg(name string, args []object.Object, want int) (*object.Array, *object.Error) {
+	if err := checkArgCount(name, args, want); err != nil {
+		return nil, err
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return arr, nil
+}
@@ -3,11 +3,15 @@ import (
 "monkey/ast"
 "monkey/object"
 "fmt"
+"math"
+"math/big"
 )
 var (
 	NULL = &object.Null{}
 	TRUE = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
+	BREAK = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
@@ -18,45 +22,60 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isAbrupt(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, right, env)
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
 		left := Eval(node.Left, env)
-		right := Eval(node.Right, env)
-		if isError(left) {
+		if isAbrupt(left) {
 			return left
 		}
-		if isError(right) {
+		right := Eval(node.Right, env)
+		if isAbrupt(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right, env)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.IfExpression:
 		condition := Eval(node.Condition, env)
-		if isError(condition) {
+		if isAbrupt(condition) {
 			return condition
 		}
-		return evalIfExpression(node, env)
+		return evalIfExpression(node, condition, env)
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 	case *ast.Program:
 		return evalProgram(node, env)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
 		env.Set(node.Name.Value, val)
@@ -64,17 +83,27 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Body: body, Env: env}
+		return &object.Function{Name: node.Name, Parameters: params, Body: body, Env: env}
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
-			return function
+		return evalCallExpression(node, env)
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isAbrupt(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isAbrupt(left) {
+			return left
 		}
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		index := Eval(node.Index, env)
+		if isAbrupt(index) {
+			return index
 		}
-		return applyFunction(function, args)
+		return evalIndexExpression(left, index)
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
 	}
 	
 
@@ -96,25 +125,35 @@ func evalStatements(stmts []ast.Statement, env *object.Environment) object.Objec
 	return result
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
+func evalPrefixExpression(operator string, right object.Object, env *object.Environment) object.Object {
 	switch operator {
 		case "!":
 			return evalBangOperatorExpression(right)
 		case "-":
-			return evalMinusPrefixOperatorExpression(right)
+			return evalMinusPrefixOperatorExpression(right, env)
+		case "~":
+			return evalBitwiseNotExpression(right)
 		default:
 			return newError("unknown operator: %s%s", operator, right.Type())
 	}
 }
 
-func evalInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+func evalInfixExpression(operator string, left object.Object, right object.Object, env *object.Environment) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right)
+		return evalIntegerInfixExpression(operator, left, right, env.CheckedArithmetic())
+	case isInteger(left) && isInteger(right):
+		return evalBigIntInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 	case operator == "!=":
 		return nativeBoolToBooleanObject(left != right)
+	case left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ:
+		return evalArrayInfixExpression(operator, left, right)
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -122,22 +161,63 @@ func evalInfixExpression(operator string, left object.Object, right object.Objec
 	}
 }
 
-func evalIntegerInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+// evalLogicalExpression evaluates && and || with short-circuiting: the right
+// operand is only evaluated if the left one does not decide the result.
+// The result is the operand that decided it, so `name || "default"` works.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+	if isTruthy(left) == (node.Operator == "||") {
+		return left
+	}
+	return Eval(node.Right, env)
+}
+
+// evalIntegerInfixExpression applies operator to two integers. Division and
+// modulo by zero are errors; results that overflow an int64 become a
+// BigInt, or an error if checked is set.
+func evalIntegerInfixExpression(operator string, left object.Object, right object.Object, checked bool) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
+	if isBitwiseOperator(operator) {
+		return evalBitwiseExpression(operator, left, right, checked)
+	}
+	switch operator {
+	case "+", "-", "*", "/", "%":
+		return evalIntegerArithmetic(operator, leftVal, rightVal, checked)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+}
+func evalStringInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		return &object.String{Value: leftVal + rightVal}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -145,8 +225,88 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
+}
+
+func evalArrayInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	if operator != "+" {
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+	leftElements := left.(*object.Array).Elements
+	rightElements := right.(*object.Array).Elements
+	elements := make([]object.Object, 0, len(leftElements)+len(rightElements))
+	elements = append(elements, leftElements...)
+	elements = append(elements, rightElements...)
+	return &object.Array{Elements: elements}
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s[%s]", left.Type(), index.Type())
+	}
+}
+
+// evalArrayIndexExpression looks up an element. Negative indexes count from
+// the end (arr[-1] is the last element); indexes out of range yield NULL.
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+	idx := index.(*object.Integer).Value
+	max := int64(len(elements))
 
+	if idx < 0 {
+		idx += max
+	}
+	if idx < 0 || idx >= max {
+		return NULL
+	}
+	return elements[idx]
 }
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isAbrupt(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isAbrupt(value) {
+			return value
+		}
+
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}
+}
+
+// evalHashIndexExpression looks up a key; missing keys yield NULL.
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+	return pair.Value
+}
+
 func evalBangOperatorExpression(right object.Object) object.Object {
 	switch right {
 		case TRUE:
@@ -161,8 +321,9 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 
 }
 
-func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
+// evalIfExpression picks the branch for the already evaluated condition,
+// so a condition with side effects runs once.
+func evalIfExpression(ie *ast.IfExpression, condition object.Object, env *object.Environment) object.Object {
 	if isTruthy(condition) {
 		return Eval(ie.Consequence, env)
 	} else if ie.Alternative != nil {
@@ -190,13 +351,16 @@ func evalReturnStatements(node *ast.ReturnStatement, env *object.Environment) ob
 	return &object.ReturnValue{Value: value}
 }
 
-	func evalProgram(program *ast.Program, env *object.Environment) object.Object {
-	var result object.Object
+// evalProgram evaluates the statements of a program. A Go panic while
+// evaluating, e.g. in a host function, is returned as an error.
+func evalProgram(program *ast.Program, env *object.Environment) (result object.Object) {
+	defer recoverPanic(&result)
+
 	for _, statement := range program.Statements {
 		result = Eval(statement, env)
-		switch result := result.(type) {
-		case *object.ReturnValue:
-			return result.Value
+		switch result.(type) {
+		case *object.ReturnValue, *object.Break, *object.Continue:
+			return unwrapReturnValue(result)
 		case *object.Error:
 			return result
 		}
@@ -210,7 +374,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 		result = Eval(statement, env)
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 			return result
 			}
 			}
@@ -218,12 +383,23 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	return result
 }
 
-func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+func evalMinusPrefixOperatorExpression(right object.Object, env *object.Environment) object.Object {
+	switch right := right.(type) {
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	case *object.BigInt:
+		return object.NewInteger(new(big.Int).Neg(right.Value))
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			if env.CheckedArithmetic() {
+				return newError("integer overflow: -(%d)", right.Value)
+			}
+			return object.NewInteger(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
@@ -237,27 +413,34 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
-func isError(obj object.Object) bool {
-	if obj != nil {
-	return obj.Type() == object.ERROR_OBJ
+// isAbrupt reports whether obj cuts evaluation short: an error, or a return,
+// break or continue signal on its way to the function or loop handling it.
+func isAbrupt(obj object.Object) bool {
+	if obj == nil {
+		return false
 	}
-	return false
+	switch obj.Type() {
+	case object.ERROR_OBJ, object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
 	}
-
+	return false
+}
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	val, ok := env.Get(node.Value)
-	if !ok {
-		return newError("identifier not found: %s", node.Value)
+	if val, ok := env.Get(node.Value); ok {
+		return val
 	}
-	return val
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+	return newError("identifier not found: %s", node.Value)
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 	for _, exp := range exps {
 		evaluated := Eval(exp, env)
-		if isError(evaluated) {
+		if isAbrupt(evaluated) {
 			return []object.Object{evaluated}
 		}
 		result = append(result, evaluated)
@@ -265,15 +448,73 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
-	function, ok := fn.(*object.Function)
-	if !ok {
-		return newError("not a function: %s", fn.Type())
+// ApplyFunction calls a Function or Builtin with args as if from code
+// evaluated in env, so host code can invoke script-defined functions.
+func ApplyFunction(env *object.Environment, fn object.Object, args ...object.Object) (result object.Object) {
+	defer recoverPanic(&result)
+	result, _ = applyFunction(fn, args, env)
+	return result
+}
+
+// maxCallDepth limits how deeply Monkey calls may nest. Go cannot recover
+// from running out of stack, so runaway recursion is stopped well before
+// that with an error instead of crashing the program embedding Monkey.
+const maxCallDepth = 10000
+
+func evalCallExpression(node *ast.CallExpression, env *object.Environment) object.Object {
+	function := Eval(node.Function, env)
+	if isAbrupt(function) {
+		return function
+	}
+	args := evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isAbrupt(args[0]) {
+		return args[0]
+	}
+
+	depth := env.CallDepth() + 1
+	if depth > maxCallDepth {
+		return newError("maximum call depth of %d exceeded", maxCallDepth)
+	}
+
+	defer unwindFrame(function, node)
+	result, entered := applyFunction(function, args, env)
+	if errObj, ok := result.(*object.Error); ok && entered && len(errObj.Stack) <= object.MaxTraceFrames {
+		// the error may be shared, e.g. returned by a host function, so
+		// the frame goes on a copy; past the frames Inspect prints none
+		// are added, so deep recursion does not copy ever longer stacks
+		withFrame := *errObj
+		withFrame.Stack = append(append([]object.Frame(nil), errObj.Stack...), newFrame(function, node))
+		return &withFrame
+	}
+	return result
+}
+
+// applyFunction calls fn with args from code evaluated in env. entered is
+// false when the call failed before fn started running, because fn is not
+// callable or got the wrong number of arguments; such errors get no stack
+// frame for fn.
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment) (result object.Object, entered bool) {
+	switch function := fn.(type) {
+	case *object.Function:
+		if len(args) != len(function.Parameters) {
+			return newError("wrong number of arguments to %s. got=%d, want=%d",
+				describeFunction(function), len(args), len(function.Parameters)), false
+		}
+		extendedEnv := extendFunctionEnv(function, args)
+		extendedEnv.SetCallDepth(env.CallDepth() + 1)
+		evaluated := Eval(function.Body, extendedEnv)
+		return unwrapReturnValue(evaluated), true
+	case *object.Builtin:
+		if function == putsBuiltin {
+			return puts(env.Output(), args), true
+		}
+		if result := function.Fn(args...); result != nil {
+			return result, true
+		}
+		return NULL, true
+	default:
+		return newError("not a function: %s", fn.Type()), false
 	}
-	
-	extendedEnv := extendFunctionEnv(function, args)
-	evaluated := Eval(function.Body, extendedEnv)
-	return unwrapReturnValue(evaluated)
 }
 
 func extendFunctionEnv(
@@ -287,9 +528,23 @@ func extendFunctionEnv(
 	return env
 }
 
+// describeFunction names a function in error messages.
+func describeFunction(fn *object.Function) string {
+	if fn.Name == "" {
+		return "anonymous function"
+	}
+	return "`" + fn.Name + "`"
+}
+
+// unwrapReturnValue turns the result of a function body into the value of
+// the call. A break or continue cannot leave the function; the parser
+// rejects them outside a loop, so one here comes from a hand-built AST.
 func unwrapReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value
+	case *object.Break, *object.Continue:
+		return newError("%s outside loop", obj.Inspect())
 	}
 	return obj
 }
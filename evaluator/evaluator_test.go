@@ -1,9 +1,12 @@
 package evaluator
 
 import (
+	"math"
+	"monkey/ast"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"strings"
 	"testing"
 )
 
@@ -31,6 +34,9 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"10 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 + 10 % 4 * 3", 8},
 	}
 
 	for _, tt := range tests {
@@ -39,6 +45,55 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0.15", "0.15"},
+		{"-2.5", "-2.5"},
+		{"1e-3", "0.001"},
+		{"100 * 0.15", "15.0"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"7 / 2.0", "3.5"},
+		{"1.5 - 2", "-0.5"},
+		{"7.5 % 2", "1.5"},
+		{"2e20", "2e+20"},
+		{"1.0 / 3", "0.3333333333333333"},
+		{"0.00001", "1e-05"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		float, ok := evaluated.(*object.Float)
+		if !ok {
+			t.Errorf("%q: object is not Float. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if float.Inspect() != tt.expected {
+			t.Errorf("%q: wrong value. expected=%s, got=%s", tt.input, tt.expected, float.Inspect())
+		}
+	}
+}
+
+func TestMixedNumberComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 == 1.0", true},
+		{"1.0 != 1", false},
+		{"0.5 < 1", true},
+		{"2 <= 1.5", false},
+		{"1.5 >= 1.5", true},
+		{"0.1 + 0.2 > 0.3", true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 // testEval is a helper function that evaluates a Monkey program string.
 // It:
 // 1. Creates a new lexer with the input
@@ -97,6 +152,19 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"true == false", false},
 		{"true != false", true},
 		{"false != true", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{`"a" <= "b"`, true},
+		{`"b" >= "c"`, false},
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 >= 3", false},
 	}
 
 	for _, tt := range tests {
@@ -177,6 +245,18 @@ func TestIfElseExpressions(t *testing.T) {
 			testNullObject(t, evaluated)
 		}
 	}
+
+	// the condition is evaluated once
+	calls := 0
+	env := object.NewEnvironment()
+	env.Set("tick", &object.Builtin{Name: "tick", Fn: func(args ...object.Object) object.Object {
+		calls++
+		return TRUE
+	}})
+	Eval(parser.New(lexer.New("if (tick()) { 1 }")).ParseProgram(), env)
+	if calls != 1 {
+		t.Errorf("condition evaluated %d times, want 1", calls)
+	}
 }
 
 func TestReturnStatements(t *testing.T) {
@@ -202,6 +282,89 @@ return 1;
 	}
 }	
 
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; while (i < 5) { let i = i + 1; } i", 5},
+		{"let i = 0; while (false) { let i = 1; } i", 0},
+		{"let sum = 0; for (x in [1, 2, 3]) { let sum = sum + x; } sum", 6},
+		{`let s = ""; for (c in "héllo") { let s = c + s; } s`, "olléh"},
+		{`let s = ""; for (k in {"b": 1, "a": 2}) { let s = s + k; } s`, "ab"},
+		{"let n = 0; for (x in []) { let n = n + 1; } n", 0},
+		{"let i = 0; while (true) { let i = i + 1; if (i == 3) { break; } } i", 3},
+		{"let sum = 0; for (x in [1, 2, 3, 4]) { if (x % 2 == 0) { continue; } let sum = sum + x; } sum", 4},
+		{"let n = 0; for (a in [1, 2]) { for (b in [1, 2, 3]) { if (b == 2) { break } let n = n + 1 } } n", 2},
+		{"let first = fn(arr) { for (x in arr) { if (x > 1) { return x; } } -1 }; first([1, 5, 7])", 5},
+		{"let first = fn(arr) { for (x in arr) { if (x > 1) { return x; } } -1 }; first([1])", -1},
+		{"let a = [1]; for (x in a) { let a = push(a, x); } len(a)", 2},
+		{"let i = 0; while (i < 100000) { let i = i + 1; } i", 100000},
+		{"let i = 0; while (i < 3) { let x = if (true) { break; }; let i = i + 1; } i", 0},
+		{"let n = 0; for (x in [1, 2]) { puts(if (true) { continue; }); let n = n + 1; } n", 0},
+		{"let n = 0; for (x in [1, 2]) { let a = [if (true) { continue; }]; let n = n + 1; } n", 0},
+		{"let f = fn() { let x = if (true) { return 7; }; 1 }; f()", 7},
+		{"let f = fn() { while (true) { return if (true) { break } else { 1 } } 2 }; f()", 2},
+		{`let f = fn() { while (true) { return if (true) { break } else { 1 } } }; let n = 0; while (n < 3) { f(); let n = n + 1; } n`, 3},
+		{"let n = 0; while (true) { let n = n + 1; let y = 1 + if (true) { break } else { 2 }; } n", 1},
+		{"let n = 0; while (true) { let n = n + 1; let y = if (true) { break } else { 2 } + puts(n); } n", 1},
+		{"let n = 0; while (true) { let n = n + 1; let y = -if (true) { break } else { 1 }; } n", 1},
+		{"let n = 0; for (x in [1, 2, 3]) { let y = [x][if (x < 3) { continue } else { 0 }]; let n = n + y; } n", 3},
+		{`let n = 0; for (x in [1, 2]) { let h = {"k": if (true) { continue } else { 1 }}; let n = n + 1; } n`, 0},
+		{"let n = 0; while (n < 5) { let n = n + 1; if (if (n > 2) { break } else { true }) { 1 } } n", 3},
+		{"let n = 0; for (x in [1, 2]) { let y = true && if (true) { continue } else { 1 }; let n = n + 1; } n", 0},
+		{"while (false) { 1 }", nil},
+		{"for (x in [1]) { x }", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("%q: wrong result. expected=%q, got=%v", tt.input, expected, evaluated)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (x in 5) { x }", "cannot iterate over INTEGER"},
+		{"while (1 / 0) { 1 }", "division by zero: 1 / 0"},
+		{"for (x in [1, 2]) { x + true; puts(x) }", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%q: no error object returned", tt.input)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestLoopSignalsDoNotLeaveFunctions(t *testing.T) {
+	// the parser rejects this, so build the function by hand
+	fn := &object.Function{
+		Body: &ast.BlockStatement{Statements: []ast.Statement{&ast.BreakStatement{}}},
+		Env:  object.NewEnvironment(),
+	}
+	errObj, ok := ApplyFunction(object.NewEnvironment(), fn).(*object.Error)
+	if !ok || errObj.Message != "break outside loop" {
+		t.Errorf("wrong result. got=%v", errObj)
+	}
+}
+
 // testNullObject is a helper function that verifies if an object
 // is NULL. Returns true if the test passes, false otherwise.
 func testNullObject(t *testing.T, obj object.Object) bool {
@@ -259,6 +422,50 @@ func TestErrorHandling(t *testing.T) {
 			"foobar",
 "identifier not found: foobar",
 		},
+		{
+			`"Hello" - "World"`,
+			"unknown operator: STRING - STRING",
+		},
+		{
+			`"Hello" * 2`,
+			"type mismatch: STRING * INTEGER",
+		},
+		{
+			"[1, 2] - [1]",
+			"unknown operator: ARRAY - ARRAY",
+		},
+		{
+			`[1, 2]["a"]`,
+			"index operator not supported: ARRAY[STRING]",
+		},
+		{
+			"5[0]",
+			"index operator not supported: INTEGER[INTEGER]",
+		},
+		{
+			`{"name": "Monkey"}[fn(x) { x }];`,
+			"unusable as hash key: FUNCTION",
+		},
+		{
+			`{[1]: 2}`,
+			"unusable as hash key: ARRAY",
+		},
+		{
+			"1 / 0",
+			"division by zero: 1 / 0",
+		},
+		{
+			"1.5 / 0",
+			"division by zero: 1.5 / 0",
+		},
+		{
+			"1.5 + true",
+			"type mismatch: FLOAT + BOOLEAN",
+		},
+		{
+			"let f = fn(x) { 10 % x }; f(0)",
+			"modulo by zero: 10 % 0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -276,6 +483,344 @@ func TestErrorHandling(t *testing.T) {
 	}
 }
 
+func TestCheckedArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		promoted string
+		overflow string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808", "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2", "-9223372036854775809", "integer overflow: -9223372036854775807 - 2"},
+		{"4611686018427387904 * 2", "9223372036854775808", "integer overflow: 4611686018427387904 * 2"},
+		{"let min = -9223372036854775807 - 1; min / -1", "9223372036854775808", "integer overflow: -9223372036854775808 / -1"},
+		{"let min = -9223372036854775807 - 1; -min", "9223372036854775808", "integer overflow: -(-9223372036854775808)"},
+		{"let f = fn(x) { x * x }; f(3037000500)", "9223372037000250000", "integer overflow: 3037000500 * 3037000500"},
+	}
+
+	for _, tt := range tests {
+		// promoted to BigInt by default
+		testBigIntObject(t, testEval(tt.input), tt.promoted)
+
+		env := object.NewEnvironment()
+		env.SetCheckedArithmetic(true)
+		evaluated := Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%q: no error object returned in checked mode. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.overflow {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.overflow, errObj.Message)
+		}
+	}
+
+	env := object.NewEnvironment()
+	env.SetCheckedArithmetic(true)
+	evaluated := Eval(parser.New(lexer.New("9223372036854775806 + 1")).ParseProgram(), env)
+	testIntegerObject(t, evaluated, math.MaxInt64)
+}
+
+func TestWrongNumberOfArguments(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"let add = fn(a, b) { a + b }; add(1)", "wrong number of arguments to `add`. got=1, want=2"},
+		{"let add = fn(a, b) { a + b }; add(1, 2, 3)", "wrong number of arguments to `add`. got=3, want=2"},
+		{"fn(x) { x }()", "wrong number of arguments to anonymous function. got=0, want=1"},
+		{"let f = fn() { 1 }; let g = f; g(1)", "wrong number of arguments to `f`. got=1, want=0"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	input := `let countdown = fn(n) {
+  if (n == 0) { len(n) } else { countdown(n - 1) }
+};
+let start = fn() { countdown(2) };
+start()`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := "ERROR: argument to `len` not supported, got INTEGER\n" +
+		"\n" +
+		"stack trace (most recent call first):\n" +
+		"len(...)\n\t2:17\n" +
+		"countdown(...)\n\t2:33\n" +
+		"countdown(...)\n\t2:33\n" +
+		"countdown(...)\n\t4:20\n" +
+		"start(...)\n\t5:1"
+	if errObj.Inspect() != expected {
+		t.Errorf("wrong stack trace.\nexpected=%q\ngot=     %q", expected, errObj.Inspect())
+	}
+
+	// errors outside any call have no trace
+	if got := testEval("1 / 0").Inspect(); got != "ERROR: division by zero: 1 / 0" {
+		t.Errorf("error outside a call wrong. got=%q", got)
+	}
+	// anonymous functions are shown as fn
+	if got := testEval("fn() { 1 / 0 }()").Inspect(); !strings.HasSuffix(got, "\nfn(...)\n\t1:1") {
+		t.Errorf("anonymous frame wrong. got=%q", got)
+	}
+	// or by the expression they were called through
+	if got := testEval("let ops = [fn(x) { x / 0 }]; ops[0](1)").Inspect(); !strings.HasSuffix(got, "\n(ops[0])(...)\n\t1:33") {
+		t.Errorf("callee frame wrong. got=%q", got)
+	}
+
+	// calls that never start running the function add no frame
+	noFrame := []struct {
+		input    string
+		expected int
+	}{
+		{"let x = 1; x()", 0},
+		{"let add = fn(a, b) { a + b }; add(1)", 0},
+		{"let f = fn() { let x = 1; x() }; f()", 1},
+	}
+	for _, tt := range noFrame {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Fatalf("%q: no error object returned", tt.input)
+		}
+		if len(errObj.Stack) != tt.expected {
+			t.Errorf("%q: wrong stack length. expected=%d, got=%d (%+v)", tt.input, tt.expected, len(errObj.Stack), errObj.Stack)
+		}
+	}
+}
+
+func TestSharedErrorNotModified(t *testing.T) {
+	shared := &object.Error{Message: "boom"}
+	env := object.NewEnvironment()
+	env.Set("fail", &object.Builtin{Name: "fail", Fn: func(args ...object.Object) object.Object {
+		return shared
+	}})
+
+	program := parser.New(lexer.New("let f = fn() { fail() }; f()")).ParseProgram()
+	for i := 0; i < 3; i++ {
+		errObj, ok := Eval(program, env).(*object.Error)
+		if !ok || len(errObj.Stack) != 2 {
+			t.Fatalf("call %d: wrong error. got=%+v", i, errObj)
+		}
+	}
+	if len(shared.Stack) != 0 {
+		t.Errorf("shared error was modified. got stack=%+v", shared.Stack)
+	}
+}
+
+func TestMaximumCallDepth(t *testing.T) {
+	evaluated := testEval("let r = fn(n) { r(n + 1) }; r(0)")
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "maximum call depth of 10000 exceeded" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+	if len(errObj.Stack) != object.MaxTraceFrames+1 {
+		t.Errorf("wrong stack length. expected=%d, got=%d", object.MaxTraceFrames+1, len(errObj.Stack))
+	}
+	if errObj.Stack[0].Function != "r" || errObj.Stack[0].Pos.String() != "1:17" {
+		t.Errorf("innermost frame wrong. got=%+v", errObj.Stack[0])
+	}
+	if !strings.HasSuffix(errObj.Inspect(), "\n...additional frames elided...") {
+		t.Errorf("elided frames not reported")
+	}
+
+	// deep recursion below the limit still works
+	testIntegerObject(t, testEval("let r = fn(n) { if (n == 0) { 0 } else { 1 + r(n - 1) } }; r(9000)"), 9000)
+}
+
+func TestGoPanicBecomesError(t *testing.T) {
+	input := `let inner = fn(x) { crash(x) };
+let outer = fn() { inner(1) };
+outer()`
+
+	env := object.NewEnvironment()
+	env.Set("crash", &object.Builtin{Name: "crash", Fn: func(args ...object.Object) object.Object {
+		var elements []object.Object
+		return elements[5] // index out of range
+	}})
+	evaluated := Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	expectedMessage := "internal error: runtime error: index out of range [5] with length 0"
+	if errObj.Message != expectedMessage {
+		t.Errorf("wrong error message. expected=%q, got=%q", expectedMessage, errObj.Message)
+	}
+
+	expectedStack := []struct {
+		function string
+		pos      string
+	}{
+		{"crash", "1:21"},
+		{"inner", "2:20"},
+		{"outer", "3:1"},
+	}
+	if len(errObj.Stack) != len(expectedStack) {
+		t.Fatalf("wrong stack length. expected=%d, got=%d (%+v)", len(expectedStack), len(errObj.Stack), errObj.Stack)
+	}
+	for i, frame := range expectedStack {
+		got := errObj.Stack[i]
+		if got.Function != frame.function || got.Pos.String() != frame.pos {
+			t.Errorf("frame %d wrong. expected=%s @%s, got=%s @%s",
+				i, frame.function, frame.pos, got.Function, got.Pos)
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	factorial := "let factorial = fn(n) { if (n < 2) { 1 } else { n * factorial(n - 1) } };"
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{factorial + "factorial(25)", "15511210043330985984000000"},
+		{factorial + "factorial(30) / factorial(28)", "870"},
+		{"let big = 9223372036854775807 + 10; big - 11", "9223372036854775806"},
+		{"let big = 9223372036854775807 * 4; big % 5", "3"},
+		{"let big = 9223372036854775807 * 4; -big", "-36893488147419103228"},
+		{"let big = 9223372036854775807 * -4; big / 3", "-12297829382473034409"},
+		{"let big = 9223372036854775807 * 2; big * 0", "0"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: wrong result. expected=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+		if fits := len(tt.expected) < 19; fits && evaluated.Type() != object.INTEGER_OBJ {
+			t.Errorf("%q: result that fits in int64 not demoted. got=%T", tt.input, evaluated)
+		}
+	}
+}
+
+func TestBigIntegerComparison(t *testing.T) {
+	big := "let big = 9223372036854775807 + 1;"
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{big + "big > 9223372036854775807", true},
+		{big + "big < 1", false},
+		{big + "-big <= -9223372036854775807", true},
+		{big + "big == 9223372036854775807 + 1", true},
+		{big + "big != big", false},
+		{big + "big - 1 == 9223372036854775807", true},
+		{big + "big == 9223372036854775808.0", true},
+		{big + "big >= 1.5", true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+
+	evaluated := testEval(big + `{big: "x"}[9223372036854775807 + 1]`)
+	if str, ok := evaluated.(*object.String); !ok || str.Value != "x" {
+		t.Errorf("BigInt hash key lookup failed. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func testBigIntObject(t *testing.T, obj object.Object, expected string) bool {
+	t.Helper()
+	result, ok := obj.(*object.BigInt)
+	if !ok {
+		t.Errorf("object is not BigInt. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value.String() != expected {
+		t.Errorf("object has wrong value. got=%s, want=%s", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"12 & 10", "8"},
+		{"12 | 10", "14"},
+		{"12 ^ 10", "6"},
+		{"~5", "-6"},
+		{"~-1", "0"},
+		{"-8 & 7", "0"},
+		{"1 << 10", "1024"},
+		{"1024 >> 3", "128"},
+		{"-16 >> 2", "-4"},
+		{"-1 >> 100", "-1"},
+		{"1 >> 64", "0"},
+		{"0 << 1000", "0"},
+		{"1 | 2 ^ 3 & 4", "3"},
+		{"1 << 2 + 1", "8"},
+		{"1 << 63", "9223372036854775808"},
+		{"-1 << 63", "-9223372036854775808"},
+		{"3 << 64", "55340232221128654848"},
+		{"(1 << 70) >> 69", "2"},
+		{"(1 << 64) | 1", "18446744073709551617"},
+		{"(1 << 64) & 1", "0"},
+		{"~(1 << 64)", "-18446744073709551617"},
+		{"(1 << 64) >> (1 << 64)", "0"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: wrong result. expected=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 << -1", "negative shift count: 1 << -1"},
+		{"1 >> -2", "negative shift count: 1 >> -2"},
+		{"(1 << 64) << -1", "negative shift count: 18446744073709551616 << -1"},
+		{"1 << (1 << 64)", "shift count too large: 1 << 18446744073709551616"},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER"},
+		{"~true", "unknown operator: ~BOOLEAN"},
+		{`"a" | "b"`, "unknown operator: STRING | STRING"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%q: no error object returned", tt.input)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+
+	env := object.NewEnvironment()
+	env.SetCheckedArithmetic(true)
+	evaluated := Eval(parser.New(lexer.New("1 << 63")).ParseProgram(), env)
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != "integer overflow: 1 << 63" {
+		t.Errorf("checked shift did not overflow. got=%v", evaluated)
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 	input string
@@ -328,4 +873,288 @@ func TestFunctionApplication(t *testing.T) {
 	for _, tt := range tests {
 		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
-}
\ No newline at end of file
+}
+func TestStringLiteral(t *testing.T) {
+	input := `"Hello World!"`
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringConcatenation(t *testing.T) {
+	input := `let greet = fn(name) { "Hello" + " " + name + "!" }; greet("World")`
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" != "a"`, false},
+		{`"a" < "b"`, true},
+		{`"b" < "a"`, false},
+		{`"b" > "a"`, true},
+		{`"ab" + "c" == "a" + "bc"`, true},
+		{`"1" == 1`, false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// the result is the operand that decided it
+		{"5 && 10", 10},
+		{"0 || 10", 0},
+		{"if (false) { 1 } || 7", 7},
+		{"let n = if (false) { 1 }; n && 3", nil},
+		// short-circuit: the right side is not evaluated
+		{"false && missing", false},
+		{"true || missing()", true},
+		{"let x = false && missing; x || 3", 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+
+	evaluated := testEval("true && missing")
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != "identifier not found: missing" {
+		t.Errorf("expected error for evaluated right operand, got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(result.Elements) != 3 {
+		t.Fatalf("array has wrong num of elements. got=%d",
+			len(result.Elements))
+	}
+
+	testIntegerObject(t, result.Elements[0], 1)
+	testIntegerObject(t, result.Elements[1], 4)
+	testIntegerObject(t, result.Elements[2], 6)
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"let i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"let myArray = [1, 2, 3]; myArray[2];", 3},
+		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]", 2},
+		{"[1, 2, 3][3]", nil},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][-4]", nil},
+		{"[][0]", nil},
+		{"[[1, 2], [3]][0][1]", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestArrayConcatenation(t *testing.T) {
+	input := "let a = [1, 2]; let b = a + [3] + []; [a, b]"
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if result.Inspect() != "[[1, 2], [1, 2, 3]]" {
+		t.Errorf("array has wrong value. got=%s", result.Inspect())
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `let two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`{1: 5}["1"]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("hello world")`, 11},
+		{`len("héllo")`, 5},
+		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len("one", "two")`, "wrong number of arguments to `len`. got=2, want=1"},
+		{`len([1, 2, 3])`, 3},
+		{`len([])`, 0},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`puts("hello", "world!")`, nil},
+		{`first([1, 2, 3])`, 1},
+		{`first([])`, nil},
+		{`first(1)`, "argument to `first` must be ARRAY, got INTEGER"},
+		{`last([1, 2, 3])`, 3},
+		{`last([])`, nil},
+		{`last(1)`, "argument to `last` must be ARRAY, got INTEGER"},
+		{`rest([1, 2, 3])`, []int{2, 3}},
+		{`rest([1])`, []int{}},
+		{`rest([])`, nil},
+		{`rest()`, "wrong number of arguments to `rest`. got=0, want=1"},
+		{`push([], 1)`, []int{1}},
+		{`let a = [1]; push(a, 2); a`, []int{1}},
+		{`push(1, 1)`, "argument to `push` must be ARRAY, got INTEGER"},
+		{`push([1])`, "wrong number of arguments to `push`. got=1, want=2"},
+		{`let len = fn(x) { 42 }; len("a")`, 42},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q",
+					expected, errObj.Message)
+			}
+		case []int:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("obj not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if len(array.Elements) != len(expected) {
+				t.Errorf("wrong num of elements. want=%d, got=%d",
+					len(expected), len(array.Elements))
+				continue
+			}
+
+			for i, expectedElem := range expected {
+				testIntegerObject(t, array.Elements[i], int64(expectedElem))
+			}
+		}
+	}
+}
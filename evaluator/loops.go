@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"sort"
+)
+
+// evalWhileStatement runs the body for as long as the condition is truthy.
+// The loop itself evaluates to null.
+func evalWhileStatement(node *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(node.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+		if result, done := evalLoopBody(node.Body, env); done {
+			return result
+		}
+	}
+}
+
+// evalForStatement binds the loop variable to each element of the iterable
+// in turn and runs the body. Arrays yield their elements, strings their
+// characters and hashes their keys, sorted by their printed form.
+// The variable lives in the enclosing environment, like a let would.
+func evalForStatement(node *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	var elements []object.Object
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		// copy, so pushing to the array in the body does not extend the loop
+		elements = append(elements, iterable.Elements...)
+	case *object.String:
+		for _, r := range iterable.Value {
+			elements = append(elements, &object.String{Value: string(r)})
+		}
+	case *object.Hash:
+		elements = hashKeys(iterable)
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	for _, element := range elements {
+		env.Set(node.Variable.Value, element)
+		if result, done := evalLoopBody(node.Body, env); done {
+			return result
+		}
+	}
+	return NULL
+}
+
+// evalLoopBody runs one iteration. It consumes break and continue, and
+// reports done with the value the loop should return when the loop has to
+// stop: null for a break, or the error or return value passing through.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	switch result := evalBlockStatement(body, env).(type) {
+	case *object.Break:
+		return NULL, true
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	}
+	return nil, false
+}
+
+func hashKeys(hash *object.Hash) []object.Object {
+	keys := make([]object.Object, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		keys = append(keys, pair.Key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Inspect() < keys[j].Inspect()
+	})
+	return keys
+}
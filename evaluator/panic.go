@@ -0,0 +1,64 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/object"
+)
+
+// hostPanic carries a Go panic up through the Monkey calls it unwinds,
+// collecting a frame for each, until recoverPanic turns it into an error.
+type hostPanic struct {
+	value interface{}
+	stack []object.Frame // innermost first
+}
+
+// unwindFrame is deferred around each Monkey call. If the call panics it
+// records the call's frame and lets the panic continue upwards.
+func unwindFrame(fn object.Object, call *ast.CallExpression) {
+	if r := recover(); r != nil {
+		p := asHostPanic(r)
+		if len(p.stack) <= object.MaxTraceFrames {
+			p.stack = append(p.stack, newFrame(fn, call))
+		}
+		panic(p)
+	}
+}
+
+// recoverPanic is deferred at the entry points of the evaluator. It stops a
+// Go panic and stores it in *result as an error with the Monkey call stack,
+// so a bug in a host function cannot crash the program embedding Monkey.
+func recoverPanic(result *object.Object) {
+	if r := recover(); r != nil {
+		p := asHostPanic(r)
+		*result = &object.Error{
+			Message: fmt.Sprintf("internal error: %v", p.value),
+			Stack:   p.stack,
+		}
+	}
+}
+
+func asHostPanic(r interface{}) *hostPanic {
+	if p, ok := r.(*hostPanic); ok {
+		return p
+	}
+	return &hostPanic{value: r}
+}
+
+// newFrame returns the frame for a call of fn at call. Frames are added to
+// errors as they pass up through calls, and to Go panics by unwindFrame.
+// A function without a name is shown as the callee expression, e.g. "ops[0]",
+// unless that is the function literal itself, which Error.Inspect shows as "fn".
+func newFrame(fn object.Object, call *ast.CallExpression) object.Frame {
+	frame := object.Frame{Pos: call.Function.Pos()}
+	switch fn := fn.(type) {
+	case *object.Function:
+		frame.Function = fn.Name
+	case *object.Builtin:
+		frame.Function = fn.Name
+	}
+	if _, literal := call.Function.(*ast.FunctionLiteral); frame.Function == "" && !literal {
+		frame.Function = call.Function.String()
+	}
+	return frame
+}
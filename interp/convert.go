@@ -0,0 +1,484 @@
+package interp
+
+import (
+	"fmt"
+	"math/big"
+	"monkey/evaluator"
+	"monkey/object"
+	"reflect"
+	"sort"
+)
+
+// tagName is the struct tag used to rename fields, e.g. `monkey:"name"`.
+// A tag of "-" leaves the field out.
+const tagName = "monkey"
+
+var (
+	objectType = reflect.TypeOf((*object.Object)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	bigIntType = reflect.TypeOf(big.Int{})
+)
+
+// ToObject converts a Go value to a Monkey value:
+// - nil and nil pointers become NULL
+// - bools, integers, floats and strings become Boolean, Integer, Float and String
+// - big.Int becomes Integer, or BigInt if it does not fit in an int64
+// - slices and arrays become Array
+// - maps with bool, integer or string keys become Hash
+// - structs become Hash keyed by field name or `monkey:"name"` tag
+// - funcs are wrapped with WrapFunc
+// Values that already are an object.Object are returned as is.
+// A value that contains itself, e.g. through a pointer, is an error.
+func ToObject(v any) (object.Object, error) {
+	obj, err := toObject(reflect.ValueOf(v))
+	if err != nil {
+		return nil, fmt.Errorf("interp: %w", err)
+	}
+	return obj, nil
+}
+
+func toObject(v reflect.Value) (object.Object, error) {
+	c := &converter{visiting: make(map[visit]bool)}
+	return c.toObject(v)
+}
+
+// converter holds the state of one ToObject call: the pointers, maps and
+// slices being converted, so a cycle is reported instead of recursing forever.
+type converter struct {
+	visiting map[visit]bool
+}
+
+// visit identifies a pointer, map or slice. Slices are told apart by length
+// as well, since a slice and its prefix share the same data pointer.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// enter marks v as being converted and returns the func that unmarks it.
+// It fails if v is already being converted further up.
+func (c *converter) enter(v reflect.Value) (leave func(), err error) {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if c.visiting[key] {
+		return nil, fmt.Errorf("encountered a cycle via %s", v.Type())
+	}
+	c.visiting[key] = true
+	return func() { delete(c.visiting, key) }, nil
+}
+
+func (c *converter) toObject(v reflect.Value) (object.Object, error) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return evaluator.NULL, nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+	}
+	if obj, ok := v.Interface().(object.Object); ok {
+		return obj, nil
+	}
+	if v.Type() == bigIntType {
+		n := v.Interface().(big.Int)
+		return object.NewInteger(new(big.Int).Set(&n)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		fallthrough
+	case reflect.Pointer:
+		leave, err := c.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return c.toObject(v.Elem())
+	case reflect.Bool:
+		return nativeBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.NewInteger(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: v.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]object.Object, v.Len())
+		for i := range elements {
+			el, err := c.toObject(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			elements[i] = el
+		}
+		return &object.Array{Elements: elements}, nil
+	case reflect.Map:
+		hash := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair, v.Len())}
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := c.toObject(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			if err := c.setPair(hash, key, iter.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return hash, nil
+	case reflect.Struct:
+		hash := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+		for _, f := range structFields(v.Type()) {
+			if err := c.setPair(hash, &object.String{Value: f.name}, v.FieldByIndex(f.index)); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.name, err)
+			}
+		}
+		return hash, nil
+	case reflect.Func:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return WrapFunc(v.Interface())
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a Monkey value", v.Type())
+	}
+}
+
+// setPair converts value and stores it in hash under key.
+func (c *converter) setPair(hash *object.Hash, key object.Object, value reflect.Value) error {
+	hashable, ok := key.(object.Hashable)
+	if !ok {
+		return fmt.Errorf("unusable as hash key: %s", key.Type())
+	}
+	val, err := c.toObject(value)
+	if err != nil {
+		return err
+	}
+	hash.Pairs[hashable.HashKey()] = object.HashPair{Key: key, Value: val}
+	return nil
+}
+
+// nativeBool returns the evaluator's shared TRUE or FALSE, which the
+// evaluator compares by identity.
+func nativeBool(b bool) *object.Boolean {
+	if b {
+		return evaluator.TRUE
+	}
+	return evaluator.FALSE
+}
+
+// FromObject stores the Monkey value obj in the Go value target points to,
+// the reverse of ToObject. Integers are range checked against the target
+// type and may be stored in floats, Hash values fill maps and structs, and
+// NULL sets the zero value.
+// An empty interface target receives int64, *big.Int, float64, string, bool, nil, []any,
+// map[string]any (or map[any]any for non-string keys) or, for functions,
+// the object itself.
+func FromObject(obj object.Object, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("interp: FromObject needs a non-nil pointer, got %T", target)
+	}
+	if err := fromObject(obj, rv.Elem()); err != nil {
+		return fmt.Errorf("interp: %w", err)
+	}
+	return nil
+}
+
+func fromObject(obj object.Object, v reflect.Value) error {
+	if v.Type() == objectType {
+		v.Set(reflect.ValueOf(obj))
+		return nil
+	}
+	if _, ok := obj.(*object.Null); ok || obj == nil {
+		v.SetZero()
+		return nil
+	}
+	if v.Type() == bigIntType {
+		switch n := obj.(type) {
+		case *object.Integer:
+			v.Set(reflect.ValueOf(*big.NewInt(n.Value)))
+			return nil
+		case *object.BigInt:
+			v.Set(reflect.ValueOf(*new(big.Int).Set(n.Value)))
+			return nil
+		}
+		return fmt.Errorf("cannot store %s in %s", obj.Type(), v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := fromObject(obj, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		native, err := toNative(obj)
+		if err != nil {
+			return err
+		}
+		if native == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(native))
+		}
+		return nil
+	case reflect.Bool:
+		if b, ok := obj.(*object.Boolean); ok {
+			v.SetBool(b.Value)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if b, ok := obj.(*object.BigInt); ok {
+			return fmt.Errorf("%s overflows %s", b.Value, v.Type())
+		}
+		if i, ok := obj.(*object.Integer); ok {
+			if v.OverflowInt(i.Value) {
+				return fmt.Errorf("%d overflows %s", i.Value, v.Type())
+			}
+			v.SetInt(i.Value)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if b, ok := obj.(*object.BigInt); ok {
+			if !b.Value.IsUint64() || v.OverflowUint(b.Value.Uint64()) {
+				return fmt.Errorf("%s overflows %s", b.Value, v.Type())
+			}
+			v.SetUint(b.Value.Uint64())
+			return nil
+		}
+		if i, ok := obj.(*object.Integer); ok {
+			if i.Value < 0 || v.OverflowUint(uint64(i.Value)) {
+				return fmt.Errorf("%d overflows %s", i.Value, v.Type())
+			}
+			v.SetUint(uint64(i.Value))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n := obj.(type) {
+		case *object.Float:
+			v.SetFloat(n.Value)
+			return nil
+		case *object.Integer:
+			v.SetFloat(float64(n.Value))
+			return nil
+		}
+	case reflect.String:
+		if s, ok := obj.(*object.String); ok {
+			v.SetString(s.Value)
+			return nil
+		}
+	case reflect.Slice:
+		if arr, ok := obj.(*object.Array); ok {
+			slice := reflect.MakeSlice(v.Type(), len(arr.Elements), len(arr.Elements))
+			for i, el := range arr.Elements {
+				if err := fromObject(el, slice.Index(i)); err != nil {
+					return fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			v.Set(slice)
+			return nil
+		}
+	case reflect.Array:
+		if arr, ok := obj.(*object.Array); ok {
+			if len(arr.Elements) != v.Len() {
+				return fmt.Errorf("cannot store ARRAY of length %d in %s", len(arr.Elements), v.Type())
+			}
+			for i, el := range arr.Elements {
+				if err := fromObject(el, v.Index(i)); err != nil {
+					return fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if hash, ok := obj.(*object.Hash); ok {
+			m := reflect.MakeMapWithSize(v.Type(), len(hash.Pairs))
+			for _, pair := range hash.Pairs {
+				key := reflect.New(v.Type().Key()).Elem()
+				if err := fromObject(pair.Key, key); err != nil {
+					return err
+				}
+				val := reflect.New(v.Type().Elem()).Elem()
+				if err := fromObject(pair.Value, val); err != nil {
+					return fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+				}
+				m.SetMapIndex(key, val)
+			}
+			v.Set(m)
+			return nil
+		}
+	case reflect.Struct:
+		if hash, ok := obj.(*object.Hash); ok {
+			for _, f := range structFields(v.Type()) {
+				key := (&object.String{Value: f.name}).HashKey()
+				pair, ok := hash.Pairs[key]
+				if !ok {
+					continue
+				}
+				if err := fromObject(pair.Value, v.FieldByIndex(f.index)); err != nil {
+					return fmt.Errorf("field %s: %w", f.name, err)
+				}
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot store %s in %s", obj.Type(), v.Type())
+}
+
+// toNative converts obj to the Go value used for empty interface targets.
+func toNative(obj object.Object) (any, error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.BigInt:
+		return new(big.Int).Set(obj.Value), nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		var out []any
+		if err := fromObject(obj, reflect.ValueOf(&out).Elem()); err != nil {
+			return nil, err
+		}
+		return out, nil
+	case *object.Hash:
+		for _, pair := range obj.Pairs {
+			if pair.Key.Type() != object.STRING_OBJ {
+				var out map[any]any
+				err := fromObject(obj, reflect.ValueOf(&out).Elem())
+				return out, err
+			}
+		}
+		var out map[string]any
+		err := fromObject(obj, reflect.ValueOf(&out).Elem())
+		return out, err
+	default:
+		return obj, nil
+	}
+}
+
+// WrapFunc turns a Go func into a Builtin. Arguments are converted with
+// FromObject to the func's parameter types (variadic funcs accept any
+// number of trailing arguments). The func may return nothing, a value,
+// an error, or a value and an error; values are converted with ToObject
+// and a non-nil error becomes an object.Error.
+func WrapFunc(fn any) (*object.Builtin, error) {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || fv.IsNil() {
+		return nil, fmt.Errorf("interp: WrapFunc needs a func, got %T", fn)
+	}
+	switch {
+	case ft.NumOut() > 2,
+		ft.NumOut() == 2 && ft.Out(1) != errorType:
+		return nil, fmt.Errorf("interp: %s must return at most a value and an error", ft)
+	}
+
+	return &object.Builtin{Fn: func(args ...object.Object) object.Object {
+		in, err := convertArgs(ft, args)
+		if err != nil {
+			return &object.Error{Message: err.Error()}
+		}
+		return convertResults(ft, fv.Call(in))
+	}}, nil
+}
+
+// convertArgs checks the argument count and converts args for a call of ft.
+func convertArgs(ft reflect.Type, args []object.Object) ([]reflect.Value, error) {
+	fixed := ft.NumIn()
+	if ft.IsVariadic() {
+		fixed--
+		if len(args) < fixed {
+			return nil, fmt.Errorf("wrong number of arguments. got=%d, want at least %d", len(args), fixed)
+		}
+	} else if len(args) != fixed {
+		return nil, fmt.Errorf("wrong number of arguments. got=%d, want=%d", len(args), fixed)
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var t reflect.Type
+		if i < fixed {
+			t = ft.In(i)
+		} else {
+			t = ft.In(fixed).Elem()
+		}
+		v := reflect.New(t).Elem()
+		if err := fromObject(arg, v); err != nil {
+			return nil, fmt.Errorf("argument %d: %s", i+1, err)
+		}
+		in[i] = v
+	}
+	return in, nil
+}
+
+// convertResults maps the Go results of a wrapped func to a single object.
+func convertResults(ft reflect.Type, out []reflect.Value) object.Object {
+	if len(out) > 0 && ft.Out(len(out)-1) == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return &object.Error{Message: err.Error()}
+		}
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return evaluator.NULL
+	}
+	obj, err := toObject(out[0])
+	if err != nil {
+		return &object.Error{Message: err.Error()}
+	}
+	return obj
+}
+
+// field describes a struct field visible to Monkey.
+type field struct {
+	name  string
+	index []int
+}
+
+// structFields lists the exported fields of t with their Monkey names,
+// sorted by name so conversions are deterministic.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup(tagName); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{name: name, index: f.Index})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	return fields
+}
@@ -0,0 +1,276 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"monkey/object"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type address struct {
+	City string `monkey:"city"`
+	Zip  string `monkey:"-"`
+}
+
+type user struct {
+	Name    string   `monkey:"name"`
+	Age     uint8    `monkey:"age"`
+	Admin   bool     `monkey:"admin"`
+	Tags    []string `monkey:"tags"`
+	Address *address `monkey:"address"`
+	Nick    string
+	secret  string
+}
+
+type node struct {
+	Value int   `monkey:"value"`
+	Next  *node `monkey:"next"`
+}
+
+func TestToObject(t *testing.T) {
+	// values reachable twice without a cycle are converted each time
+	inner := []int{1}
+	shared := []any{inner, inner, &node{Value: 1}}
+
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{nil, "null"},
+		{42, "42"},
+		{int8(-3), "-3"},
+		{uint32(7), "7"},
+		{true, "true"},
+		{"hi", "hi"},
+		{0.25, "0.25"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{new(big.Int).Lsh(big.NewInt(1), 70), "1180591620717411303424"},
+		{*big.NewInt(12), "12"},
+		{float32(2), "2.0"},
+		{[]int{1, 2, 3}, "[1, 2, 3]"},
+		{[2]bool{true, false}, "[true, false]"},
+		{[]any{1, "a", nil}, "[1, a, null]"},
+		{map[string]int{"b": 2, "a": 1}, "{a: 1, b: 2}"},
+		{map[int]bool{1: true}, "{1: true}"},
+		{(*user)(nil), "null"},
+		{
+			user{Name: "ann", Age: 30, Tags: []string{"x"}, Address: &address{City: "Oslo", Zip: "0150"}, Nick: "a", secret: "s"},
+			"{Nick: a, address: {city: Oslo}, admin: false, age: 30, name: ann, tags: [x]}",
+		},
+		{&object.Integer{Value: 5}, "5"},
+		{shared, "[[1], [1], {next: null, value: 1}]"},
+	}
+
+	for _, tt := range tests {
+		obj, err := ToObject(tt.input)
+		if err != nil {
+			t.Errorf("ToObject(%#v) returned error: %s", tt.input, err)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("ToObject(%#v) wrong. expected=%q, got=%q", tt.input, tt.expected, obj.Inspect())
+		}
+	}
+}
+
+func TestToObjectErrors(t *testing.T) {
+	loop := &node{Value: 1}
+	loop.Next = &node{Value: 2, Next: loop}
+	selfMap := map[string]any{}
+	selfMap["self"] = selfMap
+	selfSlice := []any{1, nil}
+	selfSlice[1] = selfSlice
+
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{make(chan int), "interp: cannot convert chan int to a Monkey value"},
+		{map[[1]int]int{{1}: 1}, "interp: unusable as hash key: ARRAY"},
+		{struct{ C []chan int }{C: []chan int{nil}}, "interp: field C: index 0: cannot convert chan int to a Monkey value"},
+		{loop, "interp: field next: field next: encountered a cycle via *interp.node"},
+		{selfMap, "interp: encountered a cycle via map[string]interface {}"},
+		{selfSlice, "interp: index 1: encountered a cycle via []interface {}"},
+	}
+
+	for _, tt := range tests {
+		_, err := ToObject(tt.input)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("ToObject(%T) wrong error. expected=%q, got=%v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	i := New()
+	obj, err := i.Eval(`{
+		"name": "ann",
+		"age": 30,
+		"admin": true,
+		"tags": ["x", "y"],
+		"address": {"city": "Oslo"},
+		"Nick": "a",
+		"unknown": 1
+	}`)
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+
+	var u user
+	if err := FromObject(obj, &u); err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+	expected := user{Name: "ann", Age: 30, Admin: true, Tags: []string{"x", "y"}, Address: &address{City: "Oslo"}, Nick: "a"}
+	if !reflect.DeepEqual(u, expected) {
+		t.Errorf("FromObject wrong. expected=%+v, got=%+v", expected, u)
+	}
+
+	var m map[string]int
+	obj, _ = i.Eval(`{"a": 1, "b": 2}`)
+	if err := FromObject(obj, &m); err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+	if !reflect.DeepEqual(m, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("FromObject map wrong. got=%v", m)
+	}
+
+	var native any
+	var huge *big.Int
+	obj, _ = i.Eval(`9223372036854775807 * 3`)
+	if err := FromObject(obj, &huge); err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+	if huge.String() != "27670116110564327421" {
+		t.Errorf("FromObject big.Int wrong. got=%s", huge)
+	}
+	var unsigned uint64
+	obj, _ = i.Eval(`9223372036854775807 + 1`)
+	if err := FromObject(obj, &unsigned); err != nil || unsigned != 1<<63 {
+		t.Errorf("FromObject uint64 wrong. got=%d, err=%v", unsigned, err)
+	}
+
+	var prices []float64
+	obj, _ = i.Eval(`[0.15, 2]`)
+	if err := FromObject(obj, &prices); err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+	if !reflect.DeepEqual(prices, []float64{0.15, 2}) {
+		t.Errorf("FromObject floats wrong. got=%v", prices)
+	}
+
+	obj, _ = i.Eval(`[1, "a", true, {"k": [2]}, {1: 2}, if (false) { 1 }]`)
+	if err := FromObject(obj, &native); err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+	expectedNative := []any{int64(1), "a", true, map[string]any{"k": []any{int64(2)}}, map[any]any{int64(1): int64(2)}, nil}
+	if !reflect.DeepEqual(native, expectedNative) {
+		t.Errorf("FromObject any wrong. expected=%#v, got=%#v", expectedNative, native)
+	}
+}
+
+func TestFromObjectErrors(t *testing.T) {
+	tests := []struct {
+		obj      object.Object
+		target   any
+		expected string
+	}{
+		{&object.Integer{Value: 300}, new(uint8), "interp: 300 overflows uint8"},
+		{&object.Integer{Value: -1}, new(uint), "interp: -1 overflows uint"},
+		{&object.String{Value: "x"}, new(int), "interp: cannot store STRING in int"},
+		{&object.Array{Elements: []object.Object{&object.String{Value: "x"}}}, new([]int), "interp: index 0: cannot store STRING in int"},
+		{&object.Integer{Value: 1}, 5, "interp: FromObject needs a non-nil pointer, got int"},
+		{&object.BigInt{Value: new(big.Int).Lsh(big.NewInt(1), 64)}, new(uint64), "interp: 18446744073709551616 overflows uint64"},
+		{&object.BigInt{Value: new(big.Int).Lsh(big.NewInt(1), 63)}, new(int64), "interp: 9223372036854775808 overflows int64"},
+	}
+
+	for _, tt := range tests {
+		err := FromObject(tt.obj, tt.target)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("FromObject(%s, %T) wrong error. expected=%q, got=%v", tt.obj.Inspect(), tt.target, tt.expected, err)
+		}
+	}
+}
+
+func TestWrapFunc(t *testing.T) {
+	i := New()
+	funcs := map[string]any{
+		"greet": func(u user) string { return "hi " + u.Name },
+		"sum": func(nums ...int) int {
+			total := 0
+			for _, n := range nums {
+				total += n
+			}
+			return total
+		},
+		"div": func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a / b, nil
+		},
+		"noop":  func() {},
+		"split": strings.Split,
+	}
+	for name, fn := range funcs {
+		if err := i.DefineValue(name, fn); err != nil {
+			t.Fatalf("DefineValue(%q) returned error: %s", name, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`greet({"name": "bob"})`, "hi bob"},
+		{`sum()`, "0"},
+		{`sum(1, 2, 3)`, "6"},
+		{`div(7, 2)`, "3"},
+		{`noop()`, "null"},
+		{`split("a,b", ",")`, "[a, b]"},
+		{`div(1, 0)`, "ERROR: division by zero"},
+		{`div(1)`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`div(1, "x")`, "ERROR: argument 2: cannot store STRING in int"},
+	}
+
+	for _, tt := range tests {
+		obj, err := i.Eval(tt.input)
+		var got string
+		if runtimeErr, ok := err.(*RuntimeError); ok {
+			got = "ERROR: " + runtimeErr.Err.Message
+		} else if err != nil {
+			t.Fatalf("Eval(%q) returned error: %s", tt.input, err)
+		} else {
+			got = obj.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("Eval(%q) wrong. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	// wrapped funcs are named after their binding in stack traces
+	_, err := i.Eval(`let d = div; d(1, 0)`)
+	if runtimeErr, ok := err.(*RuntimeError); !ok || len(runtimeErr.Err.Stack) != 1 || runtimeErr.Err.Stack[0].Function != "div" {
+		t.Errorf("wrapped func frame wrong. got=%v", err)
+	}
+
+	if _, err := WrapFunc(42); err == nil {
+		t.Errorf("expected error wrapping a non-func")
+	}
+	if _, err := WrapFunc(func() (int, int) { return 0, 0 }); err == nil {
+		t.Errorf("expected error wrapping a func with two values")
+	}
+}
+
+func ExampleInterpreter_DefineValue() {
+	i := New()
+	i.DefineValue("double", func(n int) int { return n * 2 })
+
+	result, _ := i.Eval("double(21)")
+	var n int
+	FromObject(result, &n)
+	fmt.Println(n)
+	// Output: 42
+}
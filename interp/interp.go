@@ -0,0 +1,174 @@
+// Package interp lets Go programs embed the Monkey interpreter: evaluate
+// scripts, expose Go values and functions to them, and call script-defined
+// functions back from Go.
+package interp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/diagnostic"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"strings"
+)
+
+// Interpreter evaluates Monkey source in a persistent environment, so
+// bindings made by one Eval are visible to the next.
+type Interpreter struct {
+	env      *object.Environment
+	filename string
+	checked  bool
+	stdout   io.Writer
+}
+
+// Option configures an Interpreter in New.
+type Option func(*Interpreter)
+
+// WithFilename sets the file name used in positions of parse diagnostics.
+func WithFilename(name string) Option {
+	return func(i *Interpreter) { i.filename = name }
+}
+
+// WithEnvironment makes the interpreter evaluate in env instead of a new one.
+func WithEnvironment(env *object.Environment) Option {
+	return func(i *Interpreter) { i.env = env }
+}
+
+// WithCheckedArithmetic makes integer overflow a runtime error instead of
+// promoting the result to an arbitrary-precision BigInt, for scripts that
+// rely on staying within int64.
+func WithCheckedArithmetic() Option {
+	return func(i *Interpreter) { i.checked = true }
+}
+
+// WithStdout makes puts print to w instead of os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) { i.stdout = w }
+}
+
+// New creates an Interpreter with an empty environment.
+func New(opts ...Option) *Interpreter {
+	i := &Interpreter{}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.env == nil {
+		i.env = object.NewEnvironment()
+	}
+	if i.checked {
+		i.env.SetCheckedArithmetic(true)
+	}
+	if i.stdout != nil {
+		i.env.SetOutput(i.stdout)
+	}
+	return i
+}
+
+// Env returns the environment scripts are evaluated in.
+func (i *Interpreter) Env() *object.Environment {
+	return i.env
+}
+
+// Define binds name to value in the global environment.
+func (i *Interpreter) Define(name string, value object.Object) {
+	i.env.Set(name, value)
+}
+
+// DefineValue converts a Go value with ToObject and binds it to name.
+// Go funcs become builtins whose arguments are converted automatically,
+// named after name in stack traces.
+func (i *Interpreter) DefineValue(name string, value any) error {
+	obj, err := ToObject(value)
+	if err != nil {
+		return err
+	}
+	if builtin, ok := obj.(*object.Builtin); ok && builtin.Name == "" {
+		named := *builtin
+		named.Name = name
+		obj = &named
+	}
+	i.Define(name, obj)
+	return nil
+}
+
+// RegisterFunc exposes a Go function to scripts under name.
+// The function should report misuse by returning an *object.Error.
+func (i *Interpreter) RegisterFunc(name string, fn func(args ...object.Object) object.Object) {
+	i.Define(name, &object.Builtin{Name: name, Fn: fn})
+}
+
+// Eval parses and evaluates src. It returns a *ParseError if src has syntax
+// errors and a *RuntimeError if the program evaluates to an error object.
+// Statements without a value (such as let) yield NULL.
+func (i *Interpreter) Eval(src string) (object.Object, error) {
+	l := lexer.NewWithFilename(i.filename, src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Diagnostics()) != 0 {
+		return nil, &ParseError{Source: src, Diagnostics: p.Diagnostics()}
+	}
+
+	return result(evaluator.Eval(program, i.env))
+}
+
+// Call invokes the function bound to fnName with args. The name is resolved
+// the way a script would resolve it, so it may be a script-defined function,
+// a registered Go function or a builtin.
+func (i *Interpreter) Call(fnName string, args ...object.Object) (object.Object, error) {
+	fn := evaluator.Eval(&ast.Identifier{Value: fnName}, i.env)
+	if _, ok := fn.(*object.Error); ok {
+		return nil, fmt.Errorf("interp: function %q is not defined", fnName)
+	}
+	switch fn.(type) {
+	case *object.Function, *object.Builtin:
+	default:
+		return nil, fmt.Errorf("interp: %q is not a function: %s", fnName, fn.Type())
+	}
+
+	return result(evaluator.ApplyFunction(i.env, fn, args...))
+}
+
+// result converts what the evaluator produced into Eval's return values.
+func result(obj object.Object) (object.Object, error) {
+	if obj == nil {
+		return evaluator.NULL, nil
+	}
+	if errObj, ok := obj.(*object.Error); ok {
+		return nil, &RuntimeError{Err: errObj}
+	}
+	return obj, nil
+}
+
+// ParseError reports the syntax errors found in a script.
+type ParseError struct {
+	Source      string
+	Diagnostics []diagnostic.Diagnostic
+}
+
+func (e *ParseError) Error() string {
+	messages := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		messages[i] = d.String()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Render writes the diagnostics with the offending source lines underlined.
+func (e *ParseError) Render() string {
+	var out bytes.Buffer
+	diagnostic.Render(&out, e.Source, e.Diagnostics)
+	return out.String()
+}
+
+// RuntimeError reports that a script evaluated to an error object.
+type RuntimeError struct {
+	Err *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Err.Message
+}
@@ -0,0 +1,175 @@
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"monkey/object"
+	"strings"
+	"testing"
+)
+
+func TestEvalKeepsBindings(t *testing.T) {
+	i := New()
+
+	if _, err := i.Eval("let double = fn(x) { x * 2 };"); err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	result, err := i.Eval("double(21)")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	testInteger(t, result, 42)
+}
+
+func TestDefineAndRegisterFunc(t *testing.T) {
+	i := New()
+	i.Define("base", &object.Integer{Value: 10})
+
+	var seen []string
+	i.RegisterFunc("record", func(args ...object.Object) object.Object {
+		for _, arg := range args {
+			seen = append(seen, arg.Inspect())
+		}
+		return &object.Integer{Value: int64(len(args))}
+	})
+
+	result, err := i.Eval(`record("a", base) + base`)
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	testInteger(t, result, 12)
+
+	if strings.Join(seen, ",") != "a,10" {
+		t.Errorf("host function got wrong arguments. got=%v", seen)
+	}
+}
+
+func TestCall(t *testing.T) {
+	i := New()
+	if _, err := i.Eval("let add = fn(a, b) { a + b };"); err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+
+	result, err := i.Call("add", &object.Integer{Value: 2}, &object.Integer{Value: 3})
+	if err != nil {
+		t.Fatalf("Call returned error: %s", err)
+	}
+	testInteger(t, result, 5)
+
+	result, err = i.Call("len", &object.String{Value: "four"})
+	if err != nil {
+		t.Fatalf("Call returned error: %s", err)
+	}
+	testInteger(t, result, 4)
+
+	if _, err := i.Call("missing"); err == nil || !strings.Contains(err.Error(), "not defined") {
+		t.Errorf("expected not defined error, got=%v", err)
+	}
+
+	i.Define("x", &object.Integer{Value: 1})
+	if _, err := i.Call("x"); err == nil || !strings.Contains(err.Error(), "not a function") {
+		t.Errorf("expected not a function error, got=%v", err)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	i := New(WithFilename("plugin.monkey"))
+
+	_, err := i.Eval("let = 1;")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *ParseError, got=%T (%v)", err, err)
+	}
+	if !strings.HasPrefix(parseErr.Error(), "plugin.monkey:1:5: ") {
+		t.Errorf("parse error missing position. got=%q", parseErr.Error())
+	}
+	if !strings.Contains(parseErr.Render(), "let = 1;") {
+		t.Errorf("rendered error missing source line. got=%q", parseErr.Render())
+	}
+
+	_, err = i.Eval("1 + true")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got=%T (%v)", err, err)
+	}
+	if runtimeErr.Error() != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong runtime error. got=%q", runtimeErr.Error())
+	}
+}
+
+func TestCheckedArithmetic(t *testing.T) {
+	_, err := New(WithCheckedArithmetic()).Eval("let big = 9223372036854775807; big + 1")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got=%T (%v)", err, err)
+	}
+	if runtimeErr.Error() != "integer overflow: 9223372036854775807 + 1" {
+		t.Errorf("wrong runtime error. got=%q", runtimeErr.Error())
+	}
+
+	result, err := New().Eval("9223372036854775807 + 1")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Type() != object.BIGINT_OBJ || result.Inspect() != "9223372036854775808" {
+		t.Errorf("expected overflow to promote to BIGINT, got=%s %s", result.Type(), result.Inspect())
+	}
+}
+
+func TestWithStdout(t *testing.T) {
+	var out bytes.Buffer
+	i := New(WithStdout(&out))
+	if _, err := i.Eval(`let greet = fn(name) { puts("hello " + name) }; greet("a"); puts(1, [2])`); err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if _, err := i.Call("puts", &object.String{Value: "b"}); err != nil {
+		t.Fatalf("Call returned error: %s", err)
+	}
+
+	expected := "hello a\n1\n[2]\nb\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestPanickingFuncBecomesError(t *testing.T) {
+	i := New()
+	i.RegisterFunc("explode", func(args ...object.Object) object.Object {
+		panic("boom")
+	})
+
+	for _, call := range []func() (object.Object, error){
+		func() (object.Object, error) { return i.Eval("explode()") },
+		func() (object.Object, error) { return i.Call("explode") },
+	} {
+		_, err := call()
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) {
+			t.Fatalf("expected *RuntimeError, got=%T (%v)", err, err)
+		}
+		if runtimeErr.Error() != "internal error: boom" {
+			t.Errorf("wrong runtime error. got=%q", runtimeErr.Error())
+		}
+	}
+}
+
+func TestEvalWithoutValue(t *testing.T) {
+	result, err := New().Eval("let a = 1;")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result.Type() != object.NULL_OBJ {
+		t.Errorf("expected NULL, got=%s", result.Type())
+	}
+}
+
+func testInteger(t *testing.T, obj object.Object, expected int64) {
+	t.Helper()
+	integer, ok := obj.(*object.Integer)
+	if !ok {
+		t.Fatalf("object is not Integer. got=%T (%+v)", obj, obj)
+	}
+	if integer.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d", integer.Value, expected)
+	}
+}
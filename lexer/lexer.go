@@ -6,24 +6,92 @@
 // lexer/lexer.go
 package lexer
 
-import "monkey/token"
+import (
+    "fmt"
+    "monkey/diagnostic"
+    "monkey/token"
+    "strings"
+    "unicode/utf8"
+)
+
+// Diagnostic codes reported by the lexer.
+const (
+    CodeUnterminatedString = "L0001" // string literal not closed before EOF
+    CodeInvalidEscape      = "L0002" // unknown or malformed escape sequence
+    CodeUnterminatedComment = "L0003" // block comment not closed before EOF
+)
 
 type Lexer struct {
     input        string
     position     int
     readPosition int
     ch           byte
+
+    // source location of ch, used to give every token a Span
+    filename string
+    line     int
+    column   int
+
+    diagnostics []diagnostic.Diagnostic
+
+    // keepComments makes NextToken return comments as COMMENT tokens
+    // instead of skipping them like whitespace
+    keepComments bool
 }
 
 func New(input string) *Lexer {
-    l := &Lexer{input: input}
+    return NewWithFilename("", input)
+}
+
+// NewWithFilename creates a lexer whose token positions carry the given file name.
+func NewWithFilename(filename, input string) *Lexer {
+    l := &Lexer{input: input, filename: filename, line: 1}
     l.readChar()
     return l
 }
 
+// Diagnostics returns the problems found while producing ILLEGAL tokens.
+func (l *Lexer) Diagnostics() []diagnostic.Diagnostic {
+    return l.diagnostics
+}
+
+// KeepComments makes the lexer return comments as token.COMMENT trivia
+// tokens, for tools like formatters that must not lose them.
+// By default comments are skipped.
+func (l *Lexer) KeepComments(keep bool) {
+    l.keepComments = keep
+}
+
+func (l *Lexer) addDiagnostic(code string, span token.Span, format string, a ...interface{}) {
+    l.diagnostics = append(l.diagnostics, diagnostic.Diagnostic{
+        Severity: diagnostic.Error,
+        Code:     code,
+        Message:  fmt.Sprintf(format, a...),
+        Span:     span,
+    })
+}
+
 func (l *Lexer) NextToken() token.Token {
+    for {
+        l.skipWhitespace()
+        start := l.currentPosition()
+        if l.isCommentStart() {
+            tok := l.readComment()
+            tok.Span = token.Span{Start: start, End: l.currentPosition()}
+            if tok.Type == token.COMMENT && !l.keepComments {
+                continue
+            }
+            return tok
+        }
+        tok := l.scanToken()
+        tok.Span = token.Span{Start: start, End: l.currentPosition()}
+        return tok
+    }
+}
+
+// scanToken reads the token starting at the current character.
+func (l *Lexer) scanToken() token.Token {
     var tok token.Token
-    l.skipWhitespace()
     switch l.ch {
     case '=':
         if l.peekChar() == '=' {
@@ -35,10 +103,19 @@ func (l *Lexer) NextToken() token.Token {
         tok = newToken(token.ASSIGN, l.ch)
         }
     case '"':
-        tok.Type = token.STRING
-        tok.Literal = l.readString()
+        start := l.position
+        if value, ok := l.readString(); ok {
+            tok.Type = token.STRING
+            tok.Literal = value
+        } else {
+            // keep the raw source so the ILLEGAL token shows what was written
+            tok.Type = token.ILLEGAL
+            tok.Literal = l.input[start:min(l.position+1, len(l.input))]
+        }
     case ';':
         tok = newToken(token.SEMICOLON, l.ch)
+    case ':':
+        tok = newToken(token.COLON, l.ch)
     case '(':
         tok = newToken(token.LPAREN, l.ch)
     case ')':
@@ -53,10 +130,42 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.SLASH, l.ch)
 	case '*':
 		tok = newToken(token.ASTERISK, l.ch)
+    case '%':
+        tok = newToken(token.PERCENT, l.ch)
     case '<':
-        tok = newToken(token.LT, l.ch)
+        switch l.peekChar() {
+        case '=':
+            tok = l.readTwoCharToken(token.LT_EQ)
+        case '<':
+            tok = l.readTwoCharToken(token.SHL)
+        default:
+            tok = newToken(token.LT, l.ch)
+        }
     case '>':
-        tok = newToken(token.GT, l.ch)
+        switch l.peekChar() {
+        case '=':
+            tok = l.readTwoCharToken(token.GT_EQ)
+        case '>':
+            tok = l.readTwoCharToken(token.SHR)
+        default:
+            tok = newToken(token.GT, l.ch)
+        }
+    case '&':
+        if l.peekChar() == '&' {
+            tok = l.readTwoCharToken(token.AND)
+        } else {
+            tok = newToken(token.BIT_AND, l.ch)
+        }
+    case '|':
+        if l.peekChar() == '|' {
+            tok = l.readTwoCharToken(token.OR)
+        } else {
+            tok = newToken(token.BIT_OR, l.ch)
+        }
+    case '^':
+        tok = newToken(token.BIT_XOR, l.ch)
+    case '~':
+        tok = newToken(token.BIT_NOT, l.ch)
     case '!':
         if l.peekChar() == '=' {
             ch := l.ch
@@ -70,6 +179,10 @@ func (l *Lexer) NextToken() token.Token {
         tok = newToken(token.LBRACE, l.ch)
     case '}':
         tok = newToken(token.RBRACE, l.ch)
+    case '[':
+        tok = newToken(token.LBRACKET, l.ch)
+    case ']':
+        tok = newToken(token.RBRACKET, l.ch)
     case 0:
         tok.Literal = ""
         tok.Type = token.EOF
@@ -83,17 +196,28 @@ func (l *Lexer) NextToken() token.Token {
         // fields past the last character of the current identifier. So we don't need the call to readChar()
         // after the switch statement again.
         } else if isDigit(l.ch) {
-            tok.Literal = l.readNumber()
-            tok.Type = token.INT
+            tok.Literal, tok.Type = l.readNumber()
             return tok
         } else {
-            tok = newToken(token.ILLEGAL, l.ch)
+            tok = l.readIllegalChar()
         }
     }
     l.readChar()
     return tok
 }
 
+// readIllegalChar makes an ILLEGAL token of the character at l.ch, reading
+// all bytes of a multi-byte UTF-8 character so the literal is the character
+// as written. It leaves l.ch on the last byte, for scanToken to step past.
+func (l *Lexer) readIllegalChar() token.Token {
+    start := l.position
+    _, size := utf8.DecodeRuneInString(l.input[start:])
+    for i := 1; i < size; i++ {
+        l.readChar()
+    }
+    return token.Token{Type: token.ILLEGAL, Literal: l.input[start : start+size]}
+}
+
 func (l *Lexer) readIdentifier() string {
     position := l.position
     for isLetter(l.ch) {
@@ -103,6 +227,7 @@ func (l *Lexer) readIdentifier() string {
 }
 
 func (l *Lexer) readChar() {
+    l.advancePosition()
     if l.readPosition >= len(l.input) {
         l.ch = 0
     } else {
@@ -112,17 +237,99 @@ func (l *Lexer) readChar() {
     l.readPosition += 1
 }
 
+// advancePosition moves line/column past the current character before it is replaced.
+// "\n", "\r\n" and a lone "\r" each count as a single line break.
+func (l *Lexer) advancePosition() {
+    switch {
+    case l.readPosition == 0:
+        // first read: ch is about to become the first character
+        l.column = 1
+    case l.position >= len(l.input):
+        // already at EOF
+    case l.ch == '\n', l.ch == '\r' && l.peekChar() != '\n':
+        l.line++
+        l.column = 1
+    case l.peekChar()&0xC0 != 0x80:
+        // only count a column once all bytes of a UTF-8 character are consumed
+        l.column++
+    }
+}
+
+// currentPosition returns the source position of the current character.
+func (l *Lexer) currentPosition() token.Position {
+    offset := l.position
+    if offset > len(l.input) {
+        offset = len(l.input)
+    }
+    return token.Position{Filename: l.filename, Offset: offset, Line: l.line, Column: l.column}
+}
+
 func isLetter(ch byte) bool { 
     // check if the character is an ascii letter, underscore, 
     return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
     }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer, or a float if the digits are followed by a
+// fraction ("0.15") and/or an exponent ("1e-3", "2.5E10"). A '.' or 'e' not
+// followed by a digit is not part of the number. Integers may have a base
+// prefix ("0xFF", "0o755", "0b1010"), and digits may be separated by
+// underscores ("1_000_000"); the parser checks that the digits are valid.
+func (l *Lexer) readNumber() (string, token.TokenType) {
     position := l.position
-    for isDigit(l.ch) {
+    tokenType := token.TokenType(token.INT)
+
+    if l.ch == '0' && isBasePrefix(l.peekChar()) {
         l.readChar()
+        l.readChar()
+        // read all letters and digits so a bad digit like the 2 in 0b102
+        // is reported as part of the literal instead of starting a new token
+        for isLetter(l.ch) || isDigit(l.ch) {
+            l.readChar()
+        }
+        return l.input[position:l.position], tokenType
     }
-    return l.input[position:l.position]
+
+    l.readDigits()
+
+    if l.ch == '.' && isDigit(l.peekChar()) {
+        tokenType = token.FLOAT
+        l.readChar()
+        l.readDigits()
+    }
+    if (l.ch == 'e' || l.ch == 'E') && l.exponentFollows() {
+        tokenType = token.FLOAT
+        l.readChar()
+        if l.ch == '+' || l.ch == '-' {
+            l.readChar()
+        }
+        l.readDigits()
+    }
+    return l.input[position:l.position], tokenType
+}
+
+// readDigits reads decimal digits and '_' separators.
+func (l *Lexer) readDigits() {
+    for isDigit(l.ch) || l.ch == '_' {
+        l.readChar()
+    }
+}
+
+func isBasePrefix(ch byte) bool {
+    switch ch {
+    case 'x', 'X', 'o', 'O', 'b', 'B':
+        return true
+    }
+    return false
+}
+
+// exponentFollows reports whether the 'e' in l.ch starts an exponent,
+// i.e. is followed by digits with an optional sign.
+func (l *Lexer) exponentFollows() bool {
+    next := l.readPosition
+    if next < len(l.input) && (l.input[next] == '+' || l.input[next] == '-') {
+        next++
+    }
+    return next < len(l.input) && isDigit(l.input[next])
 }
 
 func (l *Lexer) peekChar() byte {
@@ -136,6 +343,14 @@ func (l *Lexer) peekChar() byte {
 func isDigit(ch byte) bool {
     return '0' <= ch && ch <= '9'
 }
+// readTwoCharToken consumes the current and the next character as one
+// token, e.g. "<=". It leaves l.ch on the second character.
+func (l *Lexer) readTwoCharToken(tokenType token.TokenType) token.Token {
+    ch := l.ch
+    l.readChar()
+    return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
 func newToken(tokenType token.TokenType, ch byte) token.Token {
     return token.Token{Type: tokenType, Literal: string(ch)}
 }
@@ -147,13 +362,171 @@ func (l *Lexer) skipWhitespace() {
     }
 }
 
-func (l *Lexer) readString() string {
-    position := l.position + 1
-    for {
+func (l *Lexer) isCommentStart() bool {
+    return l.ch == '/' && (l.peekChar() == '/' || l.peekChar() == '*')
+}
+
+// readComment reads a "// ..." comment up to the end of the line, or a
+// "/* ... */" comment, which may nest. The literal is the comment as written.
+// An unterminated block comment gives an ILLEGAL token and a diagnostic.
+func (l *Lexer) readComment() token.Token {
+    start := l.currentPosition()
+    l.readChar()
+
+    if l.ch == '/' {
+        for l.ch != '\n' && l.ch != '\r' && l.ch != 0 {
+            l.readChar()
+        }
+        return token.Token{Type: token.COMMENT, Literal: l.input[start.Offset:l.position]}
+    }
+
+    l.readChar()
+    depth := 1
+    for depth > 0 {
+        switch {
+        case l.ch == 0:
+            l.addDiagnostic(CodeUnterminatedComment,
+                token.Span{Start: start, End: l.currentPosition()},
+                "unterminated block comment")
+            return token.Token{Type: token.ILLEGAL, Literal: l.input[start.Offset:]}
+        case l.ch == '/' && l.peekChar() == '*':
+            depth++
+            l.readChar()
+        case l.ch == '*' && l.peekChar() == '/':
+            depth--
+            l.readChar()
+        }
         l.readChar()
-        if l.ch == '"' || l.ch == 0 {
-            break
+    }
+    return token.Token{Type: token.COMMENT, Literal: l.input[start.Offset:l.position]}
+}
+
+// readString reads a double-quoted string, starting at the opening quote,
+// and returns its value with escape sequences decoded. It stops with l.ch on
+// the closing quote. It returns false if the string is unterminated or has an
+// invalid escape; the problem is recorded in the lexer's diagnostics.
+func (l *Lexer) readString() (string, bool) {
+    start := l.currentPosition()
+    var out strings.Builder
+    ok := true
+
+    l.readChar()
+    for l.ch != '"' {
+        switch l.ch {
+        case 0:
+            l.addDiagnostic(CodeUnterminatedString,
+                token.Span{Start: start, End: l.currentPosition()},
+                "unterminated string literal")
+            return out.String(), false
+        case '\\':
+            if !l.readEscape(&out) {
+                ok = false
+            }
+        default:
+            out.WriteByte(l.ch)
+            l.readChar()
         }
     }
-    return l.input[position:l.position]
+    return out.String(), ok
+}
+
+// simpleEscapes maps the character after a backslash to the character it stands for.
+var simpleEscapes = map[byte]byte{
+    'n':  '\n',
+    't':  '\t',
+    'r':  '\r',
+    '0':  0,
+    '\\': '\\',
+    '"':  '"',
+    '\'': '\'',
+}
+
+// readEscape decodes the escape sequence starting at the backslash in l.ch
+// and writes it to out. Supported are \n \t \r \0 \\ \" \' and \u{X...}
+// with 1 to 6 hex digits naming a Unicode code point. It leaves l.ch on the
+// first character after the escape. A closing quote or EOF is never consumed,
+// so a broken escape cannot swallow the end of the string.
+func (l *Lexer) readEscape(out *strings.Builder) bool {
+    start := l.currentPosition()
+    l.readChar()
+
+    if ch, ok := simpleEscapes[l.ch]; ok {
+        out.WriteByte(ch)
+        l.readChar()
+        return true
+    }
+
+    switch l.ch {
+    case 0:
+        // unterminated string, reported by readString
+        return false
+    case 'u':
+        return l.readUnicodeEscape(out, start)
+    default:
+        // consume the whole (possibly multi-byte) character after the backslash
+        r, size := utf8.DecodeRuneInString(l.input[l.position:])
+        for i := 0; i < size; i++ {
+            l.readChar()
+        }
+        l.addDiagnostic(CodeInvalidEscape, token.Span{Start: start, End: l.currentPosition()},
+            "invalid escape sequence \\%c", r)
+        return false
+    }
+}
+
+// readUnicodeEscape decodes the \u{X...} form with l.ch on the 'u'.
+func (l *Lexer) readUnicodeEscape(out *strings.Builder, start token.Position) bool {
+    l.readChar()
+    if l.ch != '{' {
+        l.addDiagnostic(CodeInvalidEscape, token.Span{Start: start, End: l.currentPosition()},
+            "invalid unicode escape: expected '{' after \\u")
+        return false
+    }
+    l.readChar()
+
+    digitsStart := l.position
+    for isHexDigit(l.ch) {
+        l.readChar()
+    }
+    digits := l.input[digitsStart:l.position]
+
+    if l.ch != '}' {
+        l.addDiagnostic(CodeInvalidEscape, token.Span{Start: start, End: l.currentPosition()},
+            "invalid unicode escape: expected '}' after hex digits")
+        return false
+    }
+    l.readChar()
+    span := token.Span{Start: start, End: l.currentPosition()}
+
+    if len(digits) == 0 || len(digits) > 6 {
+        l.addDiagnostic(CodeInvalidEscape, span,
+            "invalid unicode escape: \\u{%s} must have 1 to 6 hex digits", digits)
+        return false
+    }
+    var code rune
+    for i := 0; i < len(digits); i++ {
+        code = code*16 + rune(hexValue(digits[i]))
+    }
+    if !utf8.ValidRune(code) {
+        l.addDiagnostic(CodeInvalidEscape, span,
+            "invalid unicode escape: U+%X is not a valid code point", code)
+        return false
+    }
+    out.WriteRune(code)
+    return true
+}
+
+func isHexDigit(ch byte) bool {
+    return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func hexValue(ch byte) byte {
+    switch {
+    case isDigit(ch):
+        return ch - '0'
+    case 'a' <= ch && ch <= 'f':
+        return ch - 'a' + 10
+    default:
+        return ch - 'A' + 10
+    }
 }
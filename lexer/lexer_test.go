@@ -47,7 +47,7 @@ func TestNextTokenToReadFunction(t *testing.T) {
 		x + y;
 	};
 	let result = add(five, ten);
-	!-/*5;
+	!-/ *5;
 	5 < 10 > 5;
 
 	if (5 < 10) {
@@ -58,6 +58,8 @@ func TestNextTokenToReadFunction(t *testing.T) {
 
 	10 == 10;
 	10 != 9;
+	[1, 2];
+	{"foo": "bar"}
 	`
 	tests := []struct {
 		expectedType    token.TokenType
@@ -137,11 +139,299 @@ func TestNextTokenToReadFunction(t *testing.T) {
 		{token.NOT_EQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+		{token.LBRACE, "{"},
+		{token.STRING, "foo"},
+		{token.COLON, ":"},
+		{token.STRING, "bar"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong, expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong, expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\r\nx +\n  \"é\" y;"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+		expectedEndCol int
+	}{
+		{token.LET, 1, 1, 4},
+		{token.IDENT, 1, 5, 6},
+		{token.ASSIGN, 1, 7, 8},
+		{token.INT, 1, 9, 10},
+		{token.SEMICOLON, 1, 10, 11},
+		{token.IDENT, 2, 1, 2},
+		{token.PLUS, 2, 3, 4},
+		{token.STRING, 3, 3, 6},
+		{token.IDENT, 3, 7, 8},
+		{token.SEMICOLON, 3, 8, 9},
+		{token.EOF, 3, 9, 9},
+	}
+
+	l := NewWithFilename("test.monkey", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong, expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		start := tok.Span.Start
+		if start.Line != tt.expectedLine || start.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - start wrong, expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, start.Line, start.Column)
+		}
+		if tok.Span.End.Column != tt.expectedEndCol {
+			t.Fatalf("tests[%d] - end column wrong, expected=%d, got=%d",
+				i, tt.expectedEndCol, tok.Span.End.Column)
+		}
+		if start.Filename != "test.monkey" {
+			t.Fatalf("tests[%d] - filename wrong, got=%q", i, start.Filename)
+		}
+	}
+}
+
+func TestIllegalMultiByteCharacter(t *testing.T) {
+	l := New("é + 1")
+
+	tok := l.NextToken()
+	if tok.Type != token.ILLEGAL || tok.Literal != "é" {
+		t.Fatalf("wrong token. expected=ILLEGAL %q, got=%s %q", "é", tok.Type, tok.Literal)
+	}
+	if tok.Span.Start.Column != 1 || tok.Span.End.Column != 2 {
+		t.Fatalf("wrong columns. expected=1-2, got=%d-%d",
+			tok.Span.Start.Column, tok.Span.End.Column)
+	}
+	if tok.Span.End.Offset != len("é") {
+		t.Fatalf("wrong end offset. expected=%d, got=%d", len("é"), tok.Span.End.Offset)
+	}
+
+	if tok := l.NextToken(); tok.Type != token.PLUS {
+		t.Fatalf("wrong next token. expected=%q, got=%q", token.PLUS, tok.Type)
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+	}{
+		{`"foobar"`, "foobar"},
+		{`"foo bar"`, "foo bar"},
+		{`"é"`, "é"},
+		{`"a\nb"`, "a\nb"},
+		{`"a\tb\r"`, "a\tb\r"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"it\'s"`, "it's"},
+		{`"nul\0"`, "nul\x00"},
+		{`"\u{e9}"`, "é"},
+		{`"\u{1F600}!"`, "\U0001F600!"},
+		{`"\u{0041}\u{42}"`, "AB"},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != token.STRING {
+			t.Fatalf("tests[%d] - tokentype wrong, expected=%q, got=%q (%v)",
+				i, token.STRING, tok.Type, l.Diagnostics())
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong, expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after string, got=%q", i, next.Type)
+		}
+	}
+}
+
+func TestInvalidStrings(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedCode    string
+		expectedMessage string
+		expectedColumn  int
+	}{
+		{`"abc`, `"abc`, CodeUnterminatedString, "unterminated string literal", 1},
+		{`"abc\`, `"abc\`, CodeUnterminatedString, "unterminated string literal", 1},
+		{`"a\qb"`, `"a\qb"`, CodeInvalidEscape, `invalid escape sequence \q`, 3},
+		{`"\u41"`, `"\u41"`, CodeInvalidEscape, `invalid unicode escape: expected '{' after \u`, 2},
+		{`"\u{41"`, `"\u{41"`, CodeInvalidEscape, `invalid unicode escape: expected '}' after hex digits`, 2},
+		{`"\u{}"`, `"\u{}"`, CodeInvalidEscape, `invalid unicode escape: \u{} must have 1 to 6 hex digits`, 2},
+		{`"\u{D800}"`, `"\u{D800}"`, CodeInvalidEscape, `invalid unicode escape: U+D800 is not a valid code point`, 2},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != token.ILLEGAL {
+			t.Fatalf("tests[%d] - tokentype wrong, expected=%q, got=%q", i, token.ILLEGAL, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong, expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after string, got=%q", i, next.Type)
+		}
+
+		diags := l.Diagnostics()
+		if len(diags) != 1 {
+			t.Fatalf("tests[%d] - expected 1 diagnostic, got=%d", i, len(diags))
+		}
+		if diags[0].Code != tt.expectedCode {
+			t.Errorf("tests[%d] - code wrong, expected=%q, got=%q", i, tt.expectedCode, diags[0].Code)
+		}
+		if diags[0].Message != tt.expectedMessage {
+			t.Errorf("tests[%d] - message wrong, expected=%q, got=%q", i, tt.expectedMessage, diags[0].Message)
+		}
+		if diags[0].Span.Start.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - column wrong, expected=%d, got=%d", i, tt.expectedColumn, diags[0].Span.Start.Column)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 10 / 2; // trailing
+/* block /* nested */ still comment */ x
+/**/ 4 /*
+multi-line
+*/ * 3`
+
+	expected := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.COMMENT, "// leading comment"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// trailing"},
+		{token.COMMENT, "/* block /* nested */ still comment */"},
+		{token.IDENT, "x"},
+		{token.COMMENT, "/**/"},
+		{token.INT, "4"},
+		{token.COMMENT, "/*\nmulti-line\n*/"},
+		{token.ASTERISK, "*"},
+		{token.INT, "3"},
 		{token.EOF, ""},
 	}
 
+	// with comments kept as trivia
 	l := New(input)
+	l.KeepComments(true)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong, expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong, expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
 
+	// skipped by default, with positions still counting the comment lines
+	l = New(input)
+	for i, tt := range expected {
+		if tt.expectedType == token.COMMENT {
+			continue
+		}
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong, expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Type == token.ASTERISK && (tok.Span.Start.Line != 6 || tok.Span.Start.Column != 4) {
+			t.Errorf("position after comments wrong, expected=6:4, got=%s", tok.Span.Start)
+		}
+	}
+}
+
+func TestUnterminatedComment(t *testing.T) {
+	l := New("1 /* open /* nested */ still open")
+	l.NextToken()
+
+	tok := l.NextToken()
+	if tok.Type != token.ILLEGAL {
+		t.Fatalf("tokentype wrong, expected=%q, got=%q", token.ILLEGAL, tok.Type)
+	}
+	if tok.Literal != "/* open /* nested */ still open" {
+		t.Errorf("literal wrong, got=%q", tok.Literal)
+	}
+	if next := l.NextToken(); next.Type != token.EOF {
+		t.Errorf("expected EOF after comment, got=%q", next.Type)
+	}
+
+	diags := l.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != CodeUnterminatedComment {
+		t.Fatalf("expected 1 %s diagnostic, got=%v", CodeUnterminatedComment, diags)
+	}
+	if diags[0].Span.Start.Column != 3 {
+		t.Errorf("column wrong, expected=3, got=%d", diags[0].Span.Start.Column)
+	}
+}
+
+func TestTwoCharOperators(t *testing.T) {
+	input := `a <= b >= c % d && e || f < g > h & i | j ^ ~k << l >> m`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "d"},
+		{token.AND, "&&"},
+		{token.IDENT, "e"},
+		{token.OR, "||"},
+		{token.IDENT, "f"},
+		{token.LT, "<"},
+		{token.IDENT, "g"},
+		{token.GT, ">"},
+		{token.IDENT, "h"},
+		{token.BIT_AND, "&"},
+		{token.IDENT, "i"},
+		{token.BIT_OR, "|"},
+		{token.IDENT, "j"},
+		{token.BIT_XOR, "^"},
+		{token.BIT_NOT, "~"},
+		{token.IDENT, "k"},
+		{token.SHL, "<<"},
+		{token.IDENT, "l"},
+		{token.SHR, ">>"},
+		{token.IDENT, "m"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
 	for i, tt := range tests {
 		tok := l.NextToken()
 		if tok.Type != tt.expectedType {
@@ -151,4 +441,46 @@ func TestNextTokenToReadFunction(t *testing.T) {
 			t.Fatalf("tests[%d] - literal wrong, expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestNumbers(t *testing.T) {
+	input := `0.15 1e-3 2.5E10 3e+2 7 1.x 4e 5e+ 0xFF 0o755 0B1010 1_000_000 0b102 1_000.5`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "0.15"},
+		{token.FLOAT, "1e-3"},
+		{token.FLOAT, "2.5E10"},
+		{token.FLOAT, "3e+2"},
+		{token.INT, "7"},
+		// a '.' or 'e' without digits after it is not part of the number
+		{token.INT, "1"},
+		{token.ILLEGAL, "."},
+		{token.IDENT, "x"},
+		{token.INT, "4"},
+		{token.IDENT, "e"},
+		{token.INT, "5"},
+		{token.IDENT, "e"},
+		{token.PLUS, "+"},
+		{token.INT, "0xFF"},
+		{token.INT, "0o755"},
+		{token.INT, "0B1010"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0b102"}, // invalid digits are left to the parser
+		{token.FLOAT, "1_000.5"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong, expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong, expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
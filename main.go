@@ -1,20 +1,165 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"monkey/interp"
+	"monkey/object"
+	"monkey/repl"
 	"os"
 	"os/user"
-	"monkey/repl"
+	"strings"
+)
+
+// Exit codes of the monkey command.
+const (
+	exitOK           = 0
+	exitRuntimeError = 1 // the program evaluated to an error
+	exitUsage        = 2 // bad command line
+	exitParseError   = 3 // the program has syntax errors
+	exitIOError      = 4 // the script could not be read
 )
 
+const usage = `usage:
+  monkey                          start the interactive REPL
+  monkey repl                     start the interactive REPL
+  monkey run <file> [args...]     run a script ("-" reads stdin)
+  monkey <file> [args...]         same as run, for #! scripts
+  monkey eval -e '<expr>'         evaluate an expression and print the result
+
+Script arguments are available to the program in the array ` + "`args`" + `.
+Exit status is 1 for runtime errors, 2 for usage errors,
+3 for syntax errors and 4 if the script cannot be read.
+`
+
 func main() {
-	user, err := user.Current()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		return runRepl(stdin, stdout)
+	}
 
+	switch args[0] {
+	case "repl":
+		return runRepl(stdin, stdout)
+	case "run":
+		if len(args) < 2 {
+			fmt.Fprint(stderr, usage)
+			return exitUsage
+		}
+		return runFile(args[1], args[2:], stdin, stdout, stderr)
+	case "eval":
+		return runEval(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		if strings.HasPrefix(args[0], "-") && args[0] != "-" {
+			fmt.Fprintf(stderr, "monkey: unknown flag %s\n%s", args[0], usage)
+			return exitUsage
+		}
+		return runFile(args[0], args[1:], stdin, stdout, stderr)
+	}
+}
+
+func runRepl(in io.Reader, out io.Writer) int {
+	name := "there"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	fmt.Fprintf(out, "Hello %s! This is the Monkey programming language!\n", name)
+	fmt.Fprintf(out, "Feel free to type in commands\n")
+	repl.Start(in, out)
+	return exitOK
+}
+
+// runFile reads and evaluates the script at path, exposing scriptArgs as `args`.
+func runFile(path string, scriptArgs []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var src []byte
+	var err error
+	if path == "-" {
+		src, err = io.ReadAll(stdin)
+	} else {
+		src, err = os.ReadFile(path)
+	}
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(stderr, "monkey: %s\n", err)
+		return exitIOError
 	}
 
-	fmt.Printf("Hello %s! This is the Monkey programming language!\n", user.Username)
-	fmt.Printf("Feel free to type in commands\n")
-	repl.Start(os.Stdin, os.Stdout)
-}
\ No newline at end of file
+	i := interp.New(interp.WithFilename(path), interp.WithStdout(stdout))
+	defineArgs(i, scriptArgs)
+
+	_, err = i.Eval(stripShebang(string(src)))
+	return reportError(err, stderr)
+}
+
+func runEval(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	expr := flags.String("e", "", "expression to evaluate")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *expr == "" {
+		fmt.Fprintf(stderr, "monkey: eval needs an expression: monkey eval -e '<expr>'\n")
+		return exitUsage
+	}
+
+	i := interp.New(interp.WithFilename("<eval>"), interp.WithStdout(stdout))
+	defineArgs(i, flags.Args())
+
+	result, err := i.Eval(*expr)
+	if err != nil {
+		return reportError(err, stderr)
+	}
+	if result.Type() != object.NULL_OBJ {
+		fmt.Fprintln(stdout, result.Inspect())
+	}
+	return exitOK
+}
+
+// defineArgs binds the script arguments to `args` as an array of strings.
+func defineArgs(i *interp.Interpreter, scriptArgs []string) {
+	elements := make([]object.Object, len(scriptArgs))
+	for idx, arg := range scriptArgs {
+		elements[idx] = &object.String{Value: arg}
+	}
+	i.Define("args", &object.Array{Elements: elements})
+}
+
+// reportError prints err from interp.Eval and maps it to an exit code.
+func reportError(err error, stderr io.Writer) int {
+	var parseErr *interp.ParseError
+	var runtimeErr *interp.RuntimeError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &parseErr):
+		fmt.Fprint(stderr, parseErr.Render())
+		return exitParseError
+	case errors.As(err, &runtimeErr):
+		fmt.Fprintln(stderr, runtimeErr.Err.Inspect())
+		return exitRuntimeError
+	default:
+		fmt.Fprintf(stderr, "monkey: %s\n", err)
+		return exitRuntimeError
+	}
+}
+
+// stripShebang blanks out a leading "#!" line, keeping its line break so
+// positions in diagnostics still match the file.
+func stripShebang(src string) string {
+	if !strings.HasPrefix(src, "#!") {
+		return src
+	}
+	if nl := strings.IndexByte(src, '\n'); nl >= 0 {
+		return src[nl:]
+	}
+	return ""
+}
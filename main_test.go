@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	ok := write("ok.monkey", "#!/usr/bin/env monkey\nlet n = len(args);\nn;")
+	puts := write("puts.monkey", "puts(len(args), \"done\");")
+	runtimeErr := write("runtime.monkey", "let x = 1;\nx + true;")
+	parseErr := write("parse.monkey", "#!/usr/bin/env monkey\nlet = 1;")
+
+	tests := []struct {
+		args           []string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{[]string{"run", ok, "a", "b"}, exitOK, "", ""},
+		{[]string{ok}, exitOK, "", ""},
+		{[]string{"run", puts, "a"}, exitOK, "1\ndone\n", ""},
+		{[]string{"run", runtimeErr}, exitRuntimeError, "", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{[]string{"run", parseErr}, exitParseError, "", "--> " + parseErr + ":2:5"},
+		{[]string{"run", filepath.Join(dir, "missing.monkey")}, exitIOError, "", "no such file"},
+		{[]string{"run"}, exitUsage, "", "usage:"},
+		{[]string{"--bogus"}, exitUsage, "", "unknown flag --bogus"},
+		{[]string{"eval", "-e", "1 + 2"}, exitOK, "3\n", ""},
+		{[]string{"eval", "-e", "len(args)", "x", "y"}, exitOK, "2\n", ""},
+		{[]string{"eval", "-e", "let a = 1;"}, exitOK, "", ""},
+		{[]string{"eval", "-e", "puts(1 + 2)"}, exitOK, "3\n", ""},
+		{[]string{"eval", "-e", "-true"}, exitRuntimeError, "", "unknown operator: -BOOLEAN"},
+		{[]string{"eval"}, exitUsage, "", "eval needs an expression"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(tt.args, strings.NewReader(""), &stdout, &stderr)
+		if code != tt.expectedCode {
+			t.Errorf("run(%q) exit code wrong. expected=%d, got=%d (stderr=%q)",
+				tt.args, tt.expectedCode, code, stderr.String())
+		}
+		if stdout.String() != tt.expectedStdout {
+			t.Errorf("run(%q) stdout wrong. expected=%q, got=%q", tt.args, tt.expectedStdout, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), tt.expectedStderr) {
+			t.Errorf("run(%q) stderr wrong. expected to contain %q, got=%q",
+				tt.args, tt.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-"}, strings.NewReader("1 + ;"), &stdout, &stderr)
+	if code != exitParseError {
+		t.Errorf("exit code wrong. expected=%d, got=%d", exitParseError, code)
+	}
+}
@@ -1,4 +1,11 @@
 package object
+
+import (
+	"io"
+	"os"
+	"sort"
+)
+
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
@@ -13,6 +20,19 @@ func NewEnvironment() *Environment {
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+
+	// checkedArithmetic makes integer overflow an error instead of
+	// promoting the result to a BigInt.
+	// It is set on the outermost environment and read through the chain.
+	checkedArithmetic bool
+
+	// output is where puts prints. Like checkedArithmetic it is set on
+	// the outermost environment and read through the chain.
+	output io.Writer
+
+	// callDepth is the number of Monkey calls active in e, counting the
+	// call e was created for; 0 outside any function.
+	callDepth int
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -26,4 +46,58 @@ func (e *Environment) Get(name string) (Object, bool) {
 func (e *Environment) Set(name string, val Object) Object {
 	e.store[name] = val
 	return val
-}
\ No newline at end of file
+}
+// Names returns the names bound in this environment, not including
+// enclosing ones, in sorted order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetCheckedArithmetic turns checked integer arithmetic on or off for code
+// evaluated in e and in all environments enclosed by it.
+func (e *Environment) SetCheckedArithmetic(checked bool) {
+	e.checkedArithmetic = checked
+}
+
+// SetOutput makes puts print to w in code evaluated in e and in all
+// environments enclosed by it.
+func (e *Environment) SetOutput(w io.Writer) {
+	e.output = w
+}
+
+// Output returns the writer puts prints to in e: the one set on e or the
+// nearest environment enclosing it, or os.Stdout if none was set.
+func (e *Environment) Output() io.Writer {
+	for env := e; env != nil; env = env.outer {
+		if env.output != nil {
+			return env.output
+		}
+	}
+	return os.Stdout
+}
+
+// SetCallDepth records that e is the environment of a call depth calls deep.
+func (e *Environment) SetCallDepth(depth int) {
+	e.callDepth = depth
+}
+
+// CallDepth returns the number of Monkey calls active in e.
+func (e *Environment) CallDepth() int {
+	return e.callDepth
+}
+
+// CheckedArithmetic reports whether integer overflow is reported as an error,
+// i.e. whether it was turned on for e or any environment enclosing it.
+func (e *Environment) CheckedArithmetic() bool {
+	for env := e; env != nil; env = env.outer {
+		if env.checkedArithmetic {
+			return true
+		}
+	}
+	return false
+}
@@ -2,18 +2,31 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
 	"monkey/ast"
+	"monkey/token"
+	"sort"
+	"strconv"
 	"strings"
 )
 type ObjectType string
 const (
 	INTEGER_OBJ = "INTEGER"
+	FLOAT_OBJ = "FLOAT"
+	BIGINT_OBJ = "BIGINT"
 	BOOLEAN_OBJ = "BOOLEAN"
 	NULL_OBJ = "NULL"
 	STRING_OBJ = "STRING"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ = "BREAK"
+	CONTINUE_OBJ = "CONTINUE"
 	ERROR_OBJ = "ERROR"
 	FUNCTION_OBJ = "FUNCTION"
+	ARRAY_OBJ = "ARRAY"
+	HASH_OBJ = "HASH"
+	BUILTIN_OBJ = "BUILTIN"
 
 )
 
@@ -29,6 +42,47 @@ func (i *Integer) Inspect() string { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
 
+// BigInt is an integer too large for Integer. The evaluator only produces
+// one when a result does not fit in an int64; see NewInteger.
+type BigInt struct {
+	Value *big.Int
+}
+
+func (b *BigInt) Inspect() string { return b.Value.String() }
+func (b *BigInt) Type() ObjectType { return BIGINT_OBJ }
+
+// NewInteger returns n as an Integer if it fits in an int64 and as a
+// BigInt otherwise, so every integer value has a single representation.
+func NewInteger(n *big.Int) Object {
+	if n.IsInt64() {
+		return &Integer{Value: n.Int64()}
+	}
+	return &BigInt{Value: n}
+}
+
+
+type Float struct {
+	Value float64
+}
+
+// Inspect prints the shortest representation that reads back as the same
+// value, like Python: plain decimals for 1e-4 <= |x| < 1e16 ("0.15",
+// "1200.0"), exponent notation otherwise ("1e-05", "1e+16"). Whole numbers
+// get a ".0" so they don't look like integers.
+func (f *Float) Inspect() string {
+	format := byte('g')
+	if abs := math.Abs(f.Value); abs == 0 || abs >= 1e-4 && abs < 1e16 {
+		format = 'f'
+	}
+	s := strconv.FormatFloat(f.Value, format, -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+
 type Boolean struct {
 	Value bool
 }
@@ -55,16 +109,73 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string { return rv.Value.Inspect() }
 
+// Break and Continue are the signals of the break and continue statements.
+// Like ReturnValue they pass up through blocks until a loop consumes them.
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string { return "break" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string { return "continue" }
+
 
 type Error struct {
 	Message string
+	Stack   []Frame // the Monkey calls active when the error happened, innermost first; see MaxTraceFrames
+}
+
+// Frame is a function call on the Monkey call stack.
+type Frame struct {
+	Function string         // name of the called function, "" if anonymous
+	Pos      token.Position // position of the call expression
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
-func (e *Error) Inspect() string { return "ERROR: " + e.Message }
+
+// Inspect returns the message followed, if the error happened inside a
+// function call, by the call stack in the style of a Go panic:
+//
+//	ERROR: division by zero: 1 / 0
+//
+//	stack trace (most recent call first):
+//	divide(...)
+//		script.monkey:2:12
+//	average(...)
+//		script.monkey:5:1
+//
+// Each frame shows the called function and where it was called.
+func (e *Error) Inspect() string {
+	if len(e.Stack) == 0 {
+		return "ERROR: " + e.Message
+	}
+
+	var out bytes.Buffer
+	out.WriteString("ERROR: " + e.Message + "\n\nstack trace (most recent call first):")
+	for i, frame := range e.Stack {
+		if i == MaxTraceFrames {
+			out.WriteString("\n...additional frames elided...")
+			break
+		}
+		name := frame.Function
+		if name == "" {
+			name = "fn"
+		}
+		fmt.Fprintf(&out, "\n%s(...)\n\t%s", name, frame.Pos)
+	}
+	return out.String()
+}
+
+// MaxTraceFrames limits the frames Inspect prints, like Go's traceback,
+// so runaway recursion does not flood the output. The evaluator records
+// one frame more, to show that frames were elided, and drops the rest.
+const MaxTraceFrames = 100
 
 
 type Function struct {
+	Name string // name the function literal was bound to by let, "" if anonymous
 	Parameters []*ast.Identifier
 	Body *ast.BlockStatement
 	Env *Environment
@@ -84,4 +195,95 @@ func (f *Function) Inspect() string {
 	out.WriteString(f.Body.String())
 	out.WriteString("\n}")
 	return out.String()
-}
\ No newline at end of file
+}
+
+// BuiltinFunction is the Go implementation of a function provided by the host.
+type BuiltinFunction func(args ...Object) Object
+
+type Builtin struct {
+	Name string // name used in error messages and call frames
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string { return "builtin function" }
+
+type Array struct {
+	Elements []Object
+}
+
+func (ao *Array) Type() ObjectType { return ARRAY_OBJ }
+func (ao *Array) Inspect() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, e := range ao.Elements {
+		elements = append(elements, e.Inspect())
+	}
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+	return out.String()
+}
+
+
+// HashKey identifies a hashable value inside a Hash.
+// Values of different types never share a key, so 1 and "1" are distinct.
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable is implemented by objects that can be used as hash keys.
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(b.Value.String()))
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+// HashPair keeps the original key next to its value so Inspect can print it.
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+type Hash struct {
+	Pairs map[HashKey]HashPair
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+
+// Inspect prints the pairs sorted, so the output does not depend on map order.
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+	}
+	sort.Strings(pairs)
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
@@ -0,0 +1,103 @@
+package object
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff1 := &String{Value: "My name is johnny"}
+	diff2 := &String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if diff1.HashKey() != diff2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if hello1.HashKey() == diff1.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+}
+
+func TestHashKeyTypesDiffer(t *testing.T) {
+	one := &Integer{Value: 1}
+	yes := &Boolean{Value: true}
+
+	if one.HashKey() == yes.HashKey() {
+		t.Errorf("1 and true have the same hash key")
+	}
+}
+
+func TestHashInspect(t *testing.T) {
+	hash := &Hash{Pairs: map[HashKey]HashPair{}}
+	for _, pair := range []HashPair{
+		{Key: &String{Value: "b"}, Value: &Integer{Value: 2}},
+		{Key: &String{Value: "a"}, Value: &Integer{Value: 1}},
+	} {
+		hash.Pairs[pair.Key.(Hashable).HashKey()] = pair
+	}
+
+	if hash.Inspect() != "{a: 1, b: 2}" {
+		t.Errorf("hash.Inspect() wrong. got=%q", hash.Inspect())
+	}
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("z", &Integer{Value: 1})
+	env := NewEnclosedEnvironment(outer)
+	env.Set("b", &Integer{Value: 2})
+	env.Set("a", &Integer{Value: 3})
+
+	names := env.Names()
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("env.Names() wrong. got=%v", names)
+	}
+}
+
+func TestErrorInspectElidesDeepStacks(t *testing.T) {
+	err := &Error{Message: "too deep"}
+	for i := 0; i < 150; i++ {
+		err.Stack = append(err.Stack, Frame{Function: "recurse"})
+	}
+
+	inspected := err.Inspect()
+	if count := strings.Count(inspected, "recurse(...)"); count != MaxTraceFrames {
+		t.Errorf("wrong number of frames printed. expected=%d, got=%d", MaxTraceFrames, count)
+	}
+	if !strings.HasSuffix(inspected, "\n...additional frames elided...") {
+		t.Errorf("elided frames not reported. got suffix=%q", inspected[len(inspected)-40:])
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0, "0.0"},
+		{1, "1.0"},
+		{0.15, "0.15"},
+		{-2.5, "-2.5"},
+		{1200, "1200.0"},
+		{0.0001, "0.0001"},
+		{0.00001, "1e-05"},
+		{1e15, "1000000000000000.0"},
+		{1e16, "1e+16"},
+		{math.Inf(1), "+Inf"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, tt := range tests {
+		f := &Float{Value: tt.value}
+		if f.Inspect() != tt.expected {
+			t.Errorf("Float(%g).Inspect() wrong. expected=%q, got=%q", tt.value, tt.expected, f.Inspect())
+		}
+	}
+}
@@ -2,22 +2,43 @@ package parser
 
 import (
 	"monkey/ast"
+	"monkey/diagnostic"
 	"monkey/lexer"
 	"monkey/token"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+)
+
+// Diagnostic codes reported by the parser.
+const (
+	CodeUnexpectedToken = "P0001" // expectPeek saw the wrong token
+	CodeNoPrefixParseFn = "P0002" // token cannot start an expression
+	CodeInvalidInteger  = "P0003" // integer literal could not be parsed
+	CodeIllegalToken    = "P0004" // lexer produced an ILLEGAL token
+	CodeMisplacedBlock  = "P0005" // statements inside '{' where a hash literal is expected
+	CodeInvalidFloat    = "P0006" // float literal could not be parsed or is out of range
+	CodeLoopControl     = "P0007" // break or continue outside a loop
 )
 
 // Precedence levels for operator precedence parsing
 const (
 	_ int = iota
 	LOWEST      // Lowest precedence
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_AND     // &
 	EQUALS      // ==
 	LESSGREATER // > or <
+	SHIFT       // << or >>
 	SUM         // +
-	PRODUCT     // *
-	PREFIX      // -X or !X
+	PRODUCT     // * / %
+	PREFIX      // -X, !X or ~X
 	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
 // Parser represents a parser for the Monkey programming language.
@@ -25,14 +46,22 @@ const (
 // - lexer: the lexical analyzer
 // - currentToken: the current token being processed
 // - peekToken: the next token to be processed
-// - errors: list of parsing errors
+// - diagnostics: list of parsing errors
+// - panicking: set after a syntax error until the parser resynchronizes
+// - panicSpan: the span of the token that caused the current panic
+// - blockDepth: how many blocks enclose the current token
+// - loopDepth: how many loops enclose the current token within its function
 // - prefixParseFns: map of prefix parsing functions
 // - infixParseFns: map of infix parsing functions
 type Parser struct {
 	lexer          *lexer.Lexer
 	currentToken   token.Token
 	peekToken      token.Token
-	errors         []string
+	diagnostics    []diagnostic.Diagnostic
+	panicking      bool
+	panicSpan      token.Span
+	blockDepth     int
+	loopDepth      int
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }	
@@ -43,19 +72,25 @@ type Parser struct {
 // 2. Registering parsing functions for different token types
 // 3. Reading the first two tokens
 func New(lexer *lexer.Lexer) *Parser {
-	p := &Parser{lexer: lexer, errors: []string{}}
+	p := &Parser{lexer: lexer, diagnostics: []diagnostic.Diagnostic{}}
 
 	// Initialize prefix parse functions
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.BIT_NOT, p.parsePrefixExpression)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 
 	// Initialize infix parse functions
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -67,7 +102,18 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.BIT_AND, p.parseInfixExpression)
+	p.registerInfix(token.BIT_OR, p.parseInfixExpression)
+	p.registerInfix(token.BIT_XOR, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	// Read first two tokens
 	p.nextToken()
@@ -81,22 +127,37 @@ func New(lexer *lexer.Lexer) *Parser {
 func (p *Parser) nextToken() {
 	p.currentToken = p.peekToken
 	p.peekToken = p.lexer.NextToken()
+	// comments are trivia; skip them if the lexer was asked to keep them
+	for p.peekToken.Type == token.COMMENT {
+		p.peekToken = p.lexer.NextToken()
+	}
+}
+
+// Diagnostics returns the structured list of parsing errors.
+func (p *Parser) Diagnostics() []diagnostic.Diagnostic {
+	return p.diagnostics
 }
 
-// Errors returns the list of parsing errors.
+// Errors returns the list of parsing errors formatted on one line each.
 func (p *Parser) Errors() []string {
-	return p.errors
+	errors := make([]string, len(p.diagnostics))
+	for i, d := range p.diagnostics {
+		errors[i] = d.String()
+	}
+	return errors
 }
 
 // ParseProgram parses the entire program and returns an AST.
 // It iterates through all tokens until EOF, parsing each statement
 // and adding it to the program's statement list.
+// Statements with syntax errors are left out, so the returned program
+// holds every statement that parsed cleanly even when Errors() is not empty.
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
 	for p.currentToken.Type != token.EOF {
-		stmt := p.parseStatement()
+		stmt, _ := p.parseStatementWithRecovery()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -106,10 +167,76 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// parseStatementWithRecovery parses a statement and, if it reported a syntax
+// error, skips ahead to the next statement boundary (panic-mode recovery).
+// It returns nil for a statement with errors. atClose is true when the error
+// was an unexpected '}' which the caller should treat as closing its block.
+func (p *Parser) parseStatementWithRecovery() (stmt ast.Statement, atClose bool) {
+	stmt = p.parseStatement()
+	if !p.panicking {
+		return stmt, false
+	}
+	atClose = p.synchronize()
+	p.panicking = false
+	return nil, atClose
+}
+
+// synchronize skips tokens until the end of the broken statement: a ';',
+// or the point where the next token is '}', 'let', 'return' or EOF.
+// Braces opened while skipping are matched, so a malformed statement with
+// a block is skipped as a whole. It leaves the current token on the last
+// skipped token, so the caller's nextToken lands on the next statement.
+// If the error was reported at the current '}' inside a block, nothing is
+// skipped and synchronize returns true. Outside any block, a '}' that was
+// already reported ends the statement and is skipped along with a following
+// ';', so it is not parsed again as a new statement.
+func (p *Parser) synchronize() bool {
+	if p.blockDepth > 0 && p.curTokenIs(token.RBRACE) && p.currentToken.Span == p.panicSpan {
+		return true
+	}
+
+	depth := 0
+	for {
+		switch p.currentToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			if depth > 0 {
+				depth--
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				return false
+			}
+		case token.EOF:
+			return false
+		}
+
+		if depth == 0 && p.blockDepth == 0 &&
+			p.peekTokenIs(token.RBRACE) && p.peekToken.Span == p.panicSpan {
+			// the stray '}' ends the broken statement
+			p.nextToken()
+			if p.peekTokenIs(token.SEMICOLON) {
+				p.nextToken()
+			}
+			return false
+		}
+
+		if depth == 0 {
+			switch p.peekToken.Type {
+			case token.RBRACE, token.LET, token.RETURN, token.WHILE, token.FOR, token.EOF:
+				return false
+			}
+		}
+		p.nextToken()
+	}
+}
+
 // parseStatement determines the type of statement to parse based on the current token.
 // It handles:
 // - LET statements
 // - RETURN statements
+// - WHILE and FOR loops, and BREAK and CONTINUE inside them
 // - Expression statements
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currentToken.Type {
@@ -117,6 +244,12 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControlStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -144,10 +277,13 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	// name the function so errors and stack traces can refer to it
+	if fl, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+		fl.Name = stmt.Name.Value
 	}
 
+	p.skipOptionalSemicolon()
+
 	return stmt
 }
 
@@ -162,18 +298,104 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	p.nextToken()
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	p.skipOptionalSemicolon()
+
+	return stmt
+}
+
+// parseWhileStatement parses a loop in the format: while (<condition>) { <body> }
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
 	}
 
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+
+	p.skipOptionalSemicolon()
+
+	return stmt
+}
+
+// parseForStatement parses a loop in the format: for (<identifier> in <expression>) { <body> }
+func (p *Parser) parseForStatement() ast.Statement {
+	stmt := &ast.ForStatement{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Variable = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+
+	p.skipOptionalSemicolon()
+
 	return stmt
 }
 
+// parseLoopBody parses the block of a loop, in which break and continue are allowed.
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+	return p.parseBlockStatement()
+}
+
+// parseLoopControlStatement parses a break or continue statement. Both are
+// reported as errors outside a loop, including inside a function that is
+// itself defined in a loop.
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	tok := p.currentToken
+	if p.loopDepth == 0 {
+		p.addDiagnostic(CodeLoopControl, tok.Span, nil, "%s outside loop", tok.Literal)
+		return nil
+	}
+
+	p.skipOptionalSemicolon()
+
+	if tok.Type == token.BREAK {
+		return &ast.BreakStatement{Token: tok}
+	}
+	return &ast.ContinueStatement{Token: tok}
+}
+
 // noPrefixParseFnError adds an error when no prefix parse function is found
 // for the given token type.
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addDiagnostic(CodeNoPrefixParseFn, p.currentToken.Span,
+		[]string{fmt.Sprintf("%q cannot start an expression", p.currentToken.Literal)},
+		"no prefix parse function for %s found", t)
 }
 
 // parseExpression parses an expression with the given precedence.
@@ -189,7 +411,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	}
 	leftExp := prefix()
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+	for !p.panicking && !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -209,10 +431,17 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.currentToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
+	p.skipOptionalSemicolon()
+	return stmt
+}
+
+// skipOptionalSemicolon consumes the ';' ending a statement, if present.
+// While panicking it leaves the tokens alone so synchronize can see
+// exactly where the error happened.
+func (p *Parser) skipOptionalSemicolon() {
+	if !p.panicking && p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
-	return stmt
 }
 
 // curTokenIs checks if the current token is of the specified type.
@@ -239,9 +468,30 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 
 // peekError adds an error when the next token is not of the expected type.
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
-		t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	var hints []string
+	if p.peekTokenIs(token.EOF) {
+		hints = append(hints, fmt.Sprintf("the input ended before %s was found", t))
+	}
+	p.addDiagnostic(CodeUnexpectedToken, p.peekToken.Span, hints,
+		"expected next token to be %s, got %s instead", t, p.peekToken.Type)
+}
+
+// addDiagnostic records an error-severity diagnostic covering span and
+// puts the parser into panic mode. While panicking, further errors are
+// follow-on noise from the same mistake and are dropped.
+func (p *Parser) addDiagnostic(code string, span token.Span, hints []string, format string, a ...interface{}) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+	p.panicSpan = span
+	p.diagnostics = append(p.diagnostics, diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Code:     code,
+		Message:  fmt.Sprintf(format, a...),
+		Span:     span,
+		Hints:    hints,
+	})
 }
 
 // Type definitions for parse functions
@@ -260,25 +510,87 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 	p.infixParseFns[tokenType] = fn
 }
 
+// parseIllegal reports an ILLEGAL token. If the lexer explained why the token
+// is illegal (e.g. an unterminated string), that diagnostic is reported as is.
+func (p *Parser) parseIllegal() ast.Expression {
+	span := p.currentToken.Span
+	for _, d := range p.lexer.Diagnostics() {
+		offset := d.Span.Start.Offset
+		if offset >= span.Start.Offset && offset < span.End.Offset {
+			p.addDiagnostic(d.Code, d.Span, d.Hints, "%s", d.Message)
+			return nil
+		}
+	}
+	p.addDiagnostic(CodeIllegalToken, span, nil, "illegal character %q", p.currentToken.Literal)
+	return nil
+}
+
 // parseIdentifier creates an Identifier node for the current token.
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 }
 
 // parseIntegerLiteral creates an IntegerLiteral node for the current token.
-// It parses the token's literal value as an int64.
+// It parses the token's literal value as an int64, accepting the 0x, 0o and
+// 0b prefixes and '_' between digits. Literals that do not fit are reported
+// rather than truncated.
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.currentToken}
+	// ParseInt would read a C-style 0755 as octal; octal needs the 0o prefix
+	if literal := p.currentToken.Literal; len(literal) > 1 && literal[0] == '0' &&
+		(literal[1] == '_' || literal[1] >= '0' && literal[1] <= '9') {
+		p.addDiagnostic(CodeInvalidInteger, p.currentToken.Span,
+			[]string{leadingZeroHint(literal)},
+			"integer literal %s has a leading zero", literal)
+		return nil
+	}
 	value, err := strconv.ParseInt(p.currentToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		p.addDiagnostic(CodeInvalidInteger, p.currentToken.Span,
+			[]string{"the largest integer literal is 9223372036854775807"},
+			"integer literal %s is out of range", p.currentToken.Literal)
+		return nil
+	}
+	if err != nil {
+		p.addDiagnostic(CodeInvalidInteger, p.currentToken.Span, nil,
+			"could not parse %q as integer", p.currentToken.Literal)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+// leadingZeroHint suggests how to write a literal such as 0755 instead.
+func leadingZeroHint(literal string) string {
+	digits := strings.TrimLeft(literal, "0_")
+	if digits == "" {
+		digits = "0"
+	}
+	if strings.Trim(digits, "01234567_") == "" {
+		return fmt.Sprintf("write 0o%s for an octal number or %s for a decimal one", digits, digits)
+	}
+	return fmt.Sprintf("write %s without the leading zero", digits)
+}
+
+// parseFloatLiteral creates a FloatLiteral node for the current token.
+// Literals too large for a float64, such as 1e400, are reported.
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.currentToken}
+	value, err := strconv.ParseFloat(p.currentToken.Literal, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.currentToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addDiagnostic(CodeInvalidFloat, p.currentToken.Span, nil,
+			"could not parse %q as float", p.currentToken.Literal)
 		return nil
 	}
 	lit.Value = value
 	return lit
 }
 
+// parseStringLiteral creates a StringLiteral node for the current token.
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
+}
+
 // parsePrefixExpression creates a PrefixExpression node for the current token.
 // It:
 // 1. Creates the node with the current token and operator
@@ -405,18 +717,33 @@ func (p *Parser) parseIfExpression() ast.Expression {
 // It:
 // 1. Creates a BlockStatement node
 // 2. Advances past the opening brace
-// 3. Parses statements until a closing brace or EOF
+// 3. Parses statements until a closing brace or EOF, recovering from
+//    syntax errors the same way ParseProgram does
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.currentToken}
 	block.Statements = []ast.Statement{}
 
+	// recovery inside the block must not clear a panic of the enclosing statement
+	outerPanicking, outerPanicSpan := p.panicking, p.panicSpan
+	p.panicking = false
+	p.blockDepth++
+	defer func() {
+		p.blockDepth--
+		if outerPanicking {
+			p.panicking, p.panicSpan = true, outerPanicSpan
+		}
+	}()
+
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+		stmt, atClose := p.parseStatementWithRecovery()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
+		if atClose {
+			break
+		}
 		p.nextToken()
 	}
 
@@ -477,7 +804,11 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
+	// break and continue cannot reach a loop around the function
+	outerLoopDepth := p.loopDepth
+	p.loopDepth = 0
 	lit.Body = p.parseBlockStatement()
+	p.loopDepth = outerLoopDepth
 	return lit
 }
 
@@ -485,45 +816,121 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 // It handles expressions like: add(1, 2 * 3, 4 + 5)
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.currentToken, Function: function}
-	exp.Arguments = p.parseCallArguments()
+	exp.Arguments = p.parseExpressionList(token.RPAREN)
 	return exp
 }
 
-// parseCallArguments parses the arguments of a function call.
-// It handles comma-separated expressions between parentheses.
-func (p *Parser) parseCallArguments() []ast.Expression {
-	args := []ast.Expression{}
+// parseArrayLiteral parses an array literal.
+// It handles expressions like: [1, 2 * 3, fn(x) { x }]
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.currentToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	return array
+}
 
-	if p.peekTokenIs(token.RPAREN) {
+// parseHashLiteral parses a hash literal.
+// It handles expressions like: {"name": "x", 1: true}
+// Blocks are only parsed where the grammar requires one (after if, else, fn,
+// while and for), so a '{' reached through parseExpression always starts a
+// hash literal. A statement keyword right after the '{' means the user wrote
+// a block here, which gets its own diagnostic instead of a confusing
+// "no prefix" error.
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.currentToken, Pairs: []ast.HashPair{}}
+
+	switch p.peekToken.Type {
+	case token.LET, token.RETURN, token.WHILE, token.FOR, token.BREAK, token.CONTINUE:
+		p.addDiagnostic(CodeMisplacedBlock, p.peekToken.Span,
+			[]string{"blocks are only allowed after if, else, fn, while and for"},
+			"expected a hash literal, got %s statement", p.peekToken.Literal)
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
+// parseIndexExpression parses an index expression.
+// It handles expressions like: myArray[1 + 1]
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.currentToken, Left: left}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// parseExpressionList parses comma-separated expressions up to the given
+// closing token. It is shared by call arguments and array literals.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if p.peekTokenIs(end) {
 		p.nextToken()
-		return args
+		return list
 	}
 
 	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	list = append(list, p.parseExpression(LOWEST))
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		list = append(list, p.parseExpression(LOWEST))
 	}
 
-	if !p.expectPeek(token.RPAREN) {
+	if !p.expectPeek(end) {
 		return nil
 	}
 
-	return args
+	return list
 }
 
 // precedences maps token types to their precedence levels.
 var precedences = map[token.TokenType]int{
+	token.OR:       LOGICAL_OR,
+	token.AND:      LOGICAL_AND,
+	token.BIT_OR:   BIT_OR,
+	token.BIT_XOR:  BIT_XOR,
+	token.BIT_AND:  BIT_AND,
+	token.SHL:      SHIFT,
+	token.SHR:      SHIFT,
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
+	token.LT_EQ:    LESSGREATER,
+	token.GT_EQ:    LESSGREATER,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
+	token.PERCENT:  PRODUCT,
 	token.LPAREN: 	CALL,
+	token.LBRACKET: INDEX,
 }
@@ -163,6 +163,114 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_dead_beef", 0xdeadbeef},
+		{"9223372036854775807", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserError(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("%q: literal.Value not %d. got=%d", tt.input, tt.expected, literal.Value)
+		}
+	}
+}
+
+func TestInvalidIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedHints   int
+	}{
+		{"99999999999999999999", "integer literal 99999999999999999999 is out of range", 1},
+		{"0xFFFFFFFFFFFFFFFFF", "integer literal 0xFFFFFFFFFFFFFFFFF is out of range", 1},
+		{"0b102", `could not parse "0b102" as integer`, 0},
+		{"0x", `could not parse "0x" as integer`, 0},
+		{"1__000", `could not parse "1__000" as integer`, 0},
+		{"100_", `could not parse "100_" as integer`, 0},
+		{"0755", "integer literal 0755 has a leading zero", 1},
+		{"09", "integer literal 09 has a leading zero", 1},
+		{"0_7", "integer literal 0_7 has a leading zero", 1},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		diags := p.Diagnostics()
+		if len(diags) != 1 {
+			t.Fatalf("expected 1 diagnostic for %q, got=%q", tt.input, p.Errors())
+		}
+		if diags[0].Code != CodeInvalidInteger || diags[0].Message != tt.expectedMessage {
+			t.Errorf("wrong diagnostic. expected=%s %q, got=%s %q",
+				CodeInvalidInteger, tt.expectedMessage, diags[0].Code, diags[0].Message)
+		}
+		if len(diags[0].Hints) != tt.expectedHints {
+			t.Errorf("%q: wrong number of hints. expected=%d, got=%q", tt.input, tt.expectedHints, diags[0].Hints)
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"0.15;", 0.15},
+		{"1e-3;", 0.001},
+		{"2.5E2;", 250},
+		{"3e+2;", 300},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserError(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+				program.Statements[0])
+		}
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+
+	p := New(lexer.New("1e400"))
+	p.ParseProgram()
+	diags := p.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != CodeInvalidFloat {
+		t.Fatalf("expected 1 %s diagnostic, got=%q", CodeInvalidFloat, p.Errors())
+	}
+	if diags[0].Message != `could not parse "1e400" as float` {
+		t.Errorf("wrong message. got=%q", diags[0].Message)
+	}
+}
+
 // TestParsingPrefixExpressions tests the parsing of prefix expressions.
 // It verifies that the parser correctly handles prefix operators (! and -).
 func TestParsingPrefixExpressions(t *testing.T) {
@@ -173,6 +281,7 @@ func TestParsingPrefixExpressions(t *testing.T) {
 	}{
 		{"!5;", "!", 5},
 		{"-15;", "-", 15},
+		{"~15;", "~", 15},
 	}
 
 	for _, tt := range prefixTests {
@@ -272,6 +381,16 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 && 5;", 5, "&&", 5},
+		{"5 || 5;", 5, "||", 5},
+		{"5 & 5;", 5, "&", 5},
+		{"5 | 5;", 5, "|", 5},
+		{"5 ^ 5;", 5, "^", 5},
+		{"5 << 5;", 5, "<<", 5},
+		{"5 >> 5;", 5, ">>", 5},
 	}
 
 	for _, tt := range infixTests {
@@ -548,6 +667,58 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			"a + b % c * d",
+			"(a + ((b % c) * d))",
+		},
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a == b && c < d || !e",
+			"(((a == b) && (c < d)) || (!e))",
+		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a & b == c",
+			"(a & (b == c))",
+		},
+		{
+			"a && b | c",
+			"(a && (b | c))",
+		},
+		{
+			"a < b << c",
+			"(a < (b << c))",
+		},
+		{
+			"1 << 2 + 3 >> 1",
+			"((1 << (2 + 3)) >> 1)",
+		},
+		{
+			"~a & b",
+			"((~a) & b)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -707,6 +878,27 @@ func TestFunctionLiteralParsing(t *testing.T) {
 // - Empty parameter lists
 // - Single parameter
 // - Multiple parameters
+func TestFunctionLiteralWithName(t *testing.T) {
+	input := `let myFunction = fn() { };`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserError(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.LetStatement. got=%T", program.Statements[0])
+	}
+	function, ok := stmt.Value.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value is not ast.FunctionLiteral. got=%T", stmt.Value)
+	}
+	if function.Name != "myFunction" {
+		t.Errorf("function literal name wrong. want 'myFunction', got=%q", function.Name)
+	}
+}
+
 func TestFunctionParameterParsing(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -778,4 +970,427 @@ func TestCallExpressionParsing(t *testing.T) {
 	testLiteralExpression(t, exp.Arguments[0], 1)
 	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
 	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
-}
\ No newline at end of file
+}
+// TestNodePositions tests that AST nodes report the position of their token.
+func TestNodePositions(t *testing.T) {
+	input := "let x = 1;\nadd(x,\n  2 * y);"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserError(t, p)
+
+	stmt := program.Statements[1].(*ast.ExpressionStatement)
+	call := stmt.Expression.(*ast.CallExpression)
+	infix := call.Arguments[1].(*ast.InfixExpression)
+
+	tests := []struct {
+		node           ast.Node
+		expectedLine   int
+		expectedColumn int
+	}{
+		{program, 1, 1},
+		{program.Statements[0].(*ast.LetStatement).Name, 1, 5},
+		{stmt, 2, 1},
+		{call, 2, 4},
+		{call.Arguments[0], 2, 5},
+		{infix, 3, 5},
+		{infix.Right, 3, 7},
+	}
+
+	for i, tt := range tests {
+		pos := tt.node.Pos()
+		if pos.Line != tt.expectedLine || pos.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - %q position wrong. expected=%d:%d, got=%s",
+				i, tt.node.String(), tt.expectedLine, tt.expectedColumn, pos)
+		}
+	}
+}
+
+// TestParserErrorPositions tests that parser errors name the offending location.
+func TestParserErrorPositions(t *testing.T) {
+	l := lexer.New("let x = 5;\nlet = 10;")
+	p := New(l)
+	p.ParseProgram()
+
+	diags := p.Diagnostics()
+	if len(diags) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+	d := diags[0]
+	if d.Code != CodeUnexpectedToken {
+		t.Errorf("wrong code. expected=%q, got=%q", CodeUnexpectedToken, d.Code)
+	}
+	if d.Message != "expected next token to be IDENT, got = instead" {
+		t.Errorf("wrong message. got=%q", d.Message)
+	}
+	if d.Span.Start.Line != 2 || d.Span.Start.Column != 5 {
+		t.Errorf("wrong position. expected=2:5, got=%s", d.Span.Start)
+	}
+	expected := "2:5: error[P0001]: expected next token to be IDENT, got = instead"
+	if p.Errors()[0] != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, p.Errors()[0])
+	}
+}
+
+// TestErrorRecovery tests that independent syntax errors are each reported
+// once, without follow-on errors, and that valid statements are still parsed.
+func TestErrorRecovery(t *testing.T) {
+	input := `
+let = 5;
+let y 10;
+let z = );
+if (x { 1 }
+let w = 3 +;
+let ok = fn() { let a = ; a + };
+let b = [1, 2 };
+puts(1 }
+let c = 5; { c }
+ok;
+`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	expectedLines := []int{2, 3, 4, 5, 6, 7, 7, 8, 9, 10}
+	diags := p.Diagnostics()
+	if len(diags) != len(expectedLines) {
+		t.Fatalf("wrong number of diagnostics. expected=%d, got=%d: %q",
+			len(expectedLines), len(diags), p.Errors())
+	}
+	for i, line := range expectedLines {
+		if diags[i].Span.Start.Line != line {
+			t.Errorf("diagnostic[%d] on wrong line. expected=%d, got=%s",
+				i, line, diags[i].Span.Start)
+		}
+	}
+
+	expected := "let ok = fn() ;let c = 5;ok"
+	if program.String() != expected {
+		t.Errorf("partial program wrong. expected=%q, got=%q", expected, program.String())
+	}
+}
+
+// TestStringLiteralExpression tests the parsing of string literals.
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello world";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+
+	if literal.Value != "hello world" {
+		t.Errorf("literal.Value not %q. got=%q", "hello world", literal.Value)
+	}
+}
+
+// TestStringLiteralString tests that string literals print quoted and
+// escaped, so the printed program parses back to the same values.
+func TestStringLiteralString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fn() { "a, b" + "\n" }`, `fn() ("a, b" + "\n")`},
+		{`"say \"hi\"\t\\"`, `"say \"hi\"\t\\"`},
+		{`"\u{7}\0héllo"`, `"\u{7}\0héllo"`},
+	}
+
+	for _, tt := range tests {
+		program := New(lexer.New(tt.input)).ParseProgram()
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+
+		literal, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral)
+		if !ok {
+			continue
+		}
+		p := New(lexer.New(literal.String()))
+		reparsed := p.ParseProgram()
+		checkParserError(t, p)
+		value := reparsed.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral).Value
+		if value != literal.Value {
+			t.Errorf("%s does not round-trip. expected=%q, got=%q", literal, literal.Value, value)
+		}
+	}
+}
+
+// TestIllegalTokenDiagnostics tests that lexer problems are reported by the parser.
+func TestIllegalTokenDiagnostics(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedCode    string
+		expectedMessage string
+	}{
+		{`let s = "abc`, lexer.CodeUnterminatedString, "unterminated string literal"},
+		{`let s = "a\qc";`, lexer.CodeInvalidEscape, `invalid escape sequence \q`},
+		{`let s = 1; @`, CodeIllegalToken, `illegal character "@"`},
+		{`let x = é + 1`, CodeIllegalToken, `illegal character "é"`},
+		{`let s = 1; /* open`, lexer.CodeUnterminatedComment, "unterminated block comment"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		diags := p.Diagnostics()
+		if len(diags) != 1 {
+			t.Fatalf("expected 1 diagnostic for %q, got=%q", tt.input, p.Errors())
+		}
+		if diags[0].Code != tt.expectedCode || diags[0].Message != tt.expectedMessage {
+			t.Errorf("wrong diagnostic. expected=%s %q, got=%s %q",
+				tt.expectedCode, tt.expectedMessage, diags[0].Code, diags[0].Message)
+		}
+	}
+}
+
+func TestCommentsAreIgnored(t *testing.T) {
+	input := `// add two numbers
+let add = fn(a, b) { a /* left */ + b }; // trailing
+add(1, /* nested /* comment */ */ 2)`
+	expected := "let add = fn(a, b) (a + b);add(1, 2)"
+
+	for _, keep := range []bool{false, true} {
+		l := lexer.New(input)
+		l.KeepComments(keep)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserError(t, p)
+
+		if program.String() != expected {
+			t.Errorf("KeepComments(%t): program wrong. expected=%q, got=%q", keep, expected, program.String())
+		}
+	}
+}
+
+// TestParsingArrayLiterals tests the parsing of array literals.
+func TestParsingArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserError(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	}
+
+	testIntegerLiteral(t, array.Elements[0], 1)
+	testInfixExpression(t, array.Elements[1], 2, "*", 2)
+	testInfixExpression(t, array.Elements[2], 3, "+", 3)
+}
+
+// TestParsingEmptyArrayLiteral tests the parsing of an empty array literal.
+func TestParsingEmptyArrayLiteral(t *testing.T) {
+	l := lexer.New("[]")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+	if len(array.Elements) != 0 {
+		t.Errorf("len(array.Elements) not 0. got=%d", len(array.Elements))
+	}
+}
+
+// TestParsingIndexExpressions tests the parsing of index expressions.
+func TestParsingIndexExpressions(t *testing.T) {
+	input := "myArray[1 + 1]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserError(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, indexExp.Left, "myArray") {
+		return
+	}
+
+	if !testInfixExpression(t, indexExp.Index, 1, "+", 1) {
+		return
+	}
+}
+
+// TestParsingHashLiterals tests the parsing of hash literals with
+// string, integer, boolean and expression keys and values.
+func TestParsingHashLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{}`, `{}`},
+		{`{"one": 1, "two": 2, "three": 3}`, `{"one": 1, "two": 2, "three": 3}`},
+		{`{1: true, true: "x"}`, `{1: true, true: "x"}`},
+		{`{"one": 0 + 1, "two": 10 - 8}`, `{"one": (0 + 1), "two": (10 - 8)}`},
+		{`{"a": {"b": [1]}}["a"]`, `({"a": {"b": [1]}}["a"])`},
+		{`if (x) { {"k": x} }`, `ifx {"k": x}`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserError(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	l := lexer.New(`{"one": 1}`)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+	if len(hash.Pairs) != 1 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	if hash.Pairs[0].Key.String() != `"one"` {
+		t.Errorf("key wrong. got=%q", hash.Pairs[0].Key.String())
+	}
+	testIntegerLiteral(t, hash.Pairs[0].Value, 1)
+}
+
+// TestMisplacedBlock tests that a block where an expression is expected
+// is reported as such rather than as a malformed hash literal.
+func TestMisplacedBlock(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"{ let x = 1; x }", "expected a hash literal, got let statement"},
+		{"{ return 1 }", "expected a hash literal, got return statement"},
+		{"{ while (true) { 1 } }", "expected a hash literal, got while statement"},
+		{"{ for (x in []) { x } }", "expected a hash literal, got for statement"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		diags := p.Diagnostics()
+		if len(diags) != 1 {
+			t.Fatalf("%q: expected 1 diagnostic, got=%q", tt.input, p.Errors())
+		}
+		if diags[0].Code != CodeMisplacedBlock {
+			t.Errorf("wrong code. expected=%q, got=%q", CodeMisplacedBlock, diags[0].Code)
+		}
+		if diags[0].Message != tt.expectedMessage {
+			t.Errorf("wrong message. expected=%q, got=%q", tt.expectedMessage, diags[0].Message)
+		}
+		expectedHint := "blocks are only allowed after if, else, fn, while and for"
+		if len(diags[0].Hints) != 1 || diags[0].Hints[0] != expectedHint {
+			t.Errorf("wrong hints. expected=%q, got=%q", expectedHint, diags[0].Hints)
+		}
+	}
+}
+
+// TestLoopStatements tests the parsing of while and for loops together
+// with the break and continue statements inside them.
+func TestLoopStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x < 10) { let x = x + 1; }", "while ((x < 10)) { let x = (x + 1); }"},
+		{"while (true) { break; }", "while (true) { break; }"},
+		{"for (x in [1, 2]) { puts(x) }", "for (x in [1, 2]) { puts(x) }"},
+		{"for (k in keys(h)) { if (k == 1) { continue } k }", "for (k in keys(h)) { if(k == 1) continue;k }"},
+		{"while (a) { for (b in c) { break } continue }", "while (a) { for (b in c) { break; }continue; }"},
+		{"while (false) { };", "while (false) {  }"},
+		{"for (x in []) { };", "for (x in []) {  }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserError(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: program.Statements does not contain 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	program := New(lexer.New("for (item in items) { item }")).ParseProgram()
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, stmt.Variable, "item") || !testIdentifier(t, stmt.Iterable, "items") {
+		return
+	}
+	if len(stmt.Body.Statements) != 1 {
+		t.Errorf("body does not contain 1 statement. got=%d", len(stmt.Body.Statements))
+	}
+}
+
+// TestLoopControlOutsideLoop tests that break and continue are only allowed
+// inside a loop, and not in a function defined inside one.
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break;", "break outside loop"},
+		{"if (true) { continue }", "continue outside loop"},
+		{"while (true) { let f = fn() { break }; }", "break outside loop"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		diags := p.Diagnostics()
+		if len(diags) != 1 {
+			t.Errorf("%q: expected 1 diagnostic, got=%q", tt.input, p.Errors())
+			continue
+		}
+		if diags[0].Code != CodeLoopControl {
+			t.Errorf("wrong code. expected=%q, got=%q", CodeLoopControl, diags[0].Code)
+		}
+		if diags[0].Message != tt.expected {
+			t.Errorf("wrong message. expected=%q, got=%q", tt.expected, diags[0].Message)
+		}
+	}
+}
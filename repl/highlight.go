@@ -0,0 +1,162 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/token"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ANSI color sequences used by the highlighter.
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorDim     = "\x1b[2m"
+	colorBoldRed = "\x1b[1;31m"
+)
+
+// colorEnabled reports whether output to out should be colored: out must
+// be a terminal and the NO_COLOR environment variable (no-color.org) unset.
+func colorEnabled(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := out.(*os.File)
+	return ok && isTerminal(f.Fd())
+}
+
+// tokenColor returns the color for a token, or "" to leave it plain.
+func tokenColor(tok token.Token) string {
+	switch tok.Type {
+	case token.IDENT, token.EOF:
+		return ""
+	case token.INT, token.FLOAT:
+		return colorYellow
+	case token.STRING:
+		return colorGreen
+	case token.COMMENT:
+		return colorDim
+	case token.ILLEGAL:
+		// unterminated strings and comments are what they look like while
+		// being typed, not errors
+		switch {
+		case strings.HasPrefix(tok.Literal, `"`):
+			return colorGreen
+		case strings.HasPrefix(tok.Literal, "/*"):
+			return colorDim
+		}
+		return colorRed
+	case token.COMMA, token.SEMICOLON, token.COLON,
+		token.LPAREN, token.RPAREN, token.LBRACE, token.RBRACE,
+		token.LBRACKET, token.RBRACKET:
+		return ""
+	}
+	if token.LookupIdent(tok.Literal) != token.IDENT {
+		return colorMagenta
+	}
+	return colorCyan // operators
+}
+
+// Highlight returns src with ANSI colors added according to the tokens the
+// lexer finds in it. Text between tokens is copied unchanged, so the visible
+// characters, and thus cursor positions, are the same as in src.
+func Highlight(src string) string {
+	var out strings.Builder
+	l := lexer.New(src)
+	l.KeepComments(true)
+
+	// Adjacent tokens of the same color are written as one run.
+	last, runStart, runColor := 0, 0, ""
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		start, end := tok.Span.Start.Offset, tok.Span.End.Offset
+		color := tokenColor(tok)
+		if start != last || color != runColor {
+			writeColored(&out, runColor, src[runStart:last])
+			out.WriteString(src[last:start])
+			runStart, runColor = start, color
+		}
+		last = end
+	}
+	writeColored(&out, runColor, src[runStart:last])
+	out.WriteString(src[last:])
+	return out.String()
+}
+
+// colorizeObject returns the Inspect form of obj colored by its type.
+// Arrays and hashes color each element; functions are highlighted as source.
+func colorizeObject(obj object.Object) string {
+	var out strings.Builder
+	switch obj := obj.(type) {
+	case *object.Array:
+		out.WriteString("[")
+		for i, el := range obj.Elements {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(colorizeObject(el))
+		}
+		out.WriteString("]")
+	case *object.Hash:
+		// sort by the plain text, as Hash.Inspect does
+		pairs := make([]object.HashPair, 0, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			pairs = append(pairs, pair)
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return inspectPair(pairs[i]) < inspectPair(pairs[j])
+		})
+		out.WriteString("{")
+		for i, pair := range pairs {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			fmt.Fprintf(&out, "%s: %s", colorizeObject(pair.Key), colorizeObject(pair.Value))
+		}
+		out.WriteString("}")
+	case *object.Function:
+		out.WriteString(Highlight(obj.Inspect()))
+	default:
+		writeColored(&out, objectColor(obj.Type()), obj.Inspect())
+	}
+	return out.String()
+}
+
+func inspectPair(pair object.HashPair) string {
+	return fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect())
+}
+
+func objectColor(t object.ObjectType) string {
+	switch t {
+	case object.INTEGER_OBJ, object.BIGINT_OBJ, object.FLOAT_OBJ:
+		return colorYellow
+	case object.STRING_OBJ:
+		return colorGreen
+	case object.BOOLEAN_OBJ:
+		return colorMagenta
+	case object.NULL_OBJ:
+		return colorDim
+	case object.ERROR_OBJ:
+		return colorBoldRed
+	case object.BUILTIN_OBJ:
+		return colorCyan
+	}
+	return ""
+}
+
+func writeColored(out *strings.Builder, color, text string) {
+	if color == "" || text == "" {
+		out.WriteString(text)
+		return
+	}
+	out.WriteString(color)
+	out.WriteString(text)
+	out.WriteString(colorReset)
+}
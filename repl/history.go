@@ -0,0 +1,86 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is the number of lines kept in memory and in the history file.
+const maxHistory = 1000
+
+// History is the list of lines entered in the REPL, persisted to a file so
+// it survives between sessions. A History with an empty path is in-memory only.
+type History struct {
+	entries []string
+	path    string
+}
+
+// DefaultHistoryPath returns the history file in the user's config dir,
+// e.g. ~/.config/monkey/history on Linux, or "" if there is none.
+func DefaultHistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "monkey", "history")
+}
+
+// LoadHistory reads the history file at path. A missing or unreadable file
+// gives an empty history; history is a convenience and never fatal.
+func LoadHistory(path string) *History {
+	h := &History{path: path}
+	if path == "" {
+		return h
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.entries = append(h.entries, scanner.Text())
+	}
+	h.trim()
+	return h
+}
+
+// Len returns the number of entries.
+func (h *History) Len() int { return len(h.entries) }
+
+// At returns the i-th entry, oldest first.
+func (h *History) At(i int) string { return h.entries[i] }
+
+// Add appends line unless it is blank or repeats the previous entry,
+// and saves the history file.
+func (h *History) Add(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return nil
+	}
+	h.entries = append(h.entries, line)
+	h.trim()
+	return h.save()
+}
+
+func (h *History) trim() {
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+	}
+}
+
+func (h *History) save() error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	data := strings.Join(h.entries, "\n") + "\n"
+	return os.WriteFile(h.path, []byte(data), 0o600)
+}
@@ -0,0 +1,383 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+// lineReader reads one line of input after showing prompt.
+// It returns io.EOF when there is no more input.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// newLineReader returns the line editor when in and out are both a
+// terminal, and a plain line scanner otherwise (pipes, files, tests).
+func newLineReader(in io.Reader, out io.Writer, complete func(word string) []string) lineReader {
+	inFile, inOK := in.(*os.File)
+	outFile, outOK := out.(*os.File)
+	if inOK && outOK && isTerminal(inFile.Fd()) && isTerminal(outFile.Fd()) {
+		editor := newLineEditor(inFile, outFile, LoadHistory(DefaultHistoryPath()), complete)
+		return &terminalReader{fd: inFile.Fd(), editor: editor}
+	}
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+// scannerReader reads lines without any editing support.
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if r.scanner.Scan() {
+		return r.scanner.Text(), nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+// terminalReader switches the terminal to raw mode while a line is being
+// edited, so output of the evaluated program is not affected.
+type terminalReader struct {
+	fd     uintptr
+	editor *lineEditor
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	return r.editor.ReadLine(prompt)
+}
+
+// Key codes handled by the line editor.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// lineEditor is a small readline: it edits one line in a terminal in raw
+// mode, with cursor movement, history and tab completion.
+// It contains:
+//   - buf and pos: the line being edited and the cursor position in it
+//   - history: previous lines, browsed with the up and down arrows
+//   - complete: returns the candidates for the word before the cursor
+//   - highlight: if set, colors the line as it is drawn
+type lineEditor struct {
+	in        *bufio.Reader
+	out       io.Writer
+	history   *History
+	complete  func(word string) []string
+	highlight func(line string) string
+
+	prompt  string
+	buf     []rune
+	pos     int
+	histIdx int    // index into history while browsing; history.Len() when not
+	pending []rune // the unsubmitted line, kept while browsing history
+}
+
+func newLineEditor(in io.Reader, out io.Writer, history *History, complete func(word string) []string) *lineEditor {
+	if history == nil {
+		history = &History{}
+	}
+	return &lineEditor{in: bufio.NewReader(in), out: out, history: history, complete: complete}
+}
+
+// ReadLine shows prompt and lets the user edit a line until Enter.
+// It returns io.EOF for Ctrl-D on an empty line and ErrInterrupted for Ctrl-C.
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	e.prompt = prompt
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.histIdx = e.history.Len()
+	e.pending = nil
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(e.buf) > 0 {
+				return e.submit(), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			return e.submit(), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case keyBackspace, '\b':
+			e.deleteBackward()
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.moveLeft()
+		case keyCtrlF:
+			e.moveRight()
+		case keyCtrlP:
+			e.historyPrev()
+		case keyCtrlN:
+			e.historyNext()
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append(e.buf[:0], e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			e.deleteWord()
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyTab:
+			e.completeWord()
+		case keyEscape:
+			e.escapeSequence()
+		default:
+			if unicode.IsPrint(r) {
+				e.insert(r)
+			}
+		}
+		e.refresh()
+	}
+}
+
+// submit ends the line, records it in the history and returns it.
+func (e *lineEditor) submit() string {
+	e.pos = len(e.buf)
+	e.refresh()
+	fmt.Fprint(e.out, "\r\n")
+	line := string(e.buf)
+	e.history.Add(line)
+	return line
+}
+
+// escapeSequence handles the ANSI sequences sent by the arrow, Home, End
+// and Delete keys, e.g. "ESC [ A" for up or "ESC [ 3 ~" for delete.
+func (e *lineEditor) escapeSequence() {
+	intro, _, err := e.in.ReadRune()
+	if err != nil || (intro != '[' && intro != 'O') {
+		return
+	}
+	var param strings.Builder
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return
+		}
+		if r >= '0' && r <= '9' || r == ';' {
+			param.WriteRune(r)
+			continue
+		}
+		e.escapeKey(r, param.String())
+		return
+	}
+}
+
+func (e *lineEditor) escapeKey(final rune, param string) {
+	switch final {
+	case 'A':
+		e.historyPrev()
+	case 'B':
+		e.historyNext()
+	case 'C':
+		e.moveRight()
+	case 'D':
+		e.moveLeft()
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '~':
+		switch param {
+		case "1", "7":
+			e.pos = 0
+		case "4", "8":
+			e.pos = len(e.buf)
+		case "3":
+			e.deleteForward()
+		}
+	}
+}
+
+func (e *lineEditor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *lineEditor) insertString(s string) {
+	for _, r := range s {
+		e.insert(r)
+	}
+}
+
+func (e *lineEditor) deleteBackward() {
+	if e.pos == 0 {
+		return
+	}
+	e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+	e.pos--
+}
+
+func (e *lineEditor) deleteForward() {
+	if e.pos == len(e.buf) {
+		return
+	}
+	e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+}
+
+// deleteWord deletes the word before the cursor and the spaces after it.
+func (e *lineEditor) deleteWord() {
+	start := e.pos
+	for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+func (e *lineEditor) moveLeft() {
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *lineEditor) moveRight() {
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+func (e *lineEditor) historyPrev() {
+	if e.histIdx == 0 {
+		return
+	}
+	if e.histIdx == e.history.Len() {
+		e.pending = append([]rune(nil), e.buf...)
+	}
+	e.histIdx--
+	e.setLine([]rune(e.history.At(e.histIdx)))
+}
+
+func (e *lineEditor) historyNext() {
+	if e.histIdx == e.history.Len() {
+		return
+	}
+	e.histIdx++
+	if e.histIdx == e.history.Len() {
+		e.setLine(e.pending)
+		return
+	}
+	e.setLine([]rune(e.history.At(e.histIdx)))
+}
+
+func (e *lineEditor) setLine(line []rune) {
+	e.buf = append(e.buf[:0], line...)
+	e.pos = len(e.buf)
+}
+
+// completeWord completes the word before the cursor. A single candidate is
+// inserted in full; otherwise the longest common prefix of the candidates
+// is inserted, and if that adds nothing the candidates are listed.
+func (e *lineEditor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	word := wordBefore(e.buf, e.pos)
+	candidates := e.complete(word)
+	switch len(candidates) {
+	case 0:
+		fmt.Fprint(e.out, "\a")
+	case 1:
+		e.insertString(strings.TrimPrefix(candidates[0], word))
+	default:
+		prefix := commonPrefix(candidates)
+		if len(prefix) > len(word) {
+			e.insertString(strings.TrimPrefix(prefix, word))
+			return
+		}
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
+
+// wordBefore returns the identifier ending at pos, including a leading
+// ':' when it starts the line so meta-commands can be completed.
+func wordBefore(buf []rune, pos int) string {
+	start := pos
+	for start > 0 && isWordRune(buf[start-1]) {
+		start--
+	}
+	if start == 1 && buf[0] == ':' {
+		start = 0
+	}
+	return string(buf[start:pos])
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// refresh redraws the prompt and line and puts the cursor back in place.
+func (e *lineEditor) refresh() {
+	line := string(e.buf)
+	if e.highlight != nil {
+		line = e.highlight(line)
+	}
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, line)
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,135 @@
+package repl
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"os"
+	"strings"
+	"time"
+)
+
+// metaCommand is a REPL command starting with ':' that inspects the
+// interpreter instead of evaluating Monkey code.
+// takesSource marks commands whose argument is Monkey source, which may
+// continue over several lines like normal input.
+type metaCommand struct {
+	usage       string
+	help        string
+	takesSource bool
+	run         func(s *session, arg string)
+}
+
+var metaCommands map[string]metaCommand
+
+func init() {
+	metaCommands = map[string]metaCommand{
+		"tokens": {":tokens <code>", "print the tokens the lexer produces for code", true, (*session).printTokens},
+		"ast":    {":ast <code>", "print the syntax tree the parser builds for code", true, (*session).printAST},
+		"env":    {":env", "list the bindings in the session environment", false, (*session).printEnv},
+		"load":   {":load <file>", "evaluate a file in the session", false, (*session).load},
+		"reset":  {":reset", "discard all bindings", false, (*session).reset},
+		"time":   {":time <code>", "evaluate code and print how long it took", true, (*session).timeEval},
+		"help":   {":help", "list the meta-commands", false, (*session).printHelp},
+	}
+}
+
+// isMetaCommand reports whether input is a meta-command rather than code.
+func isMetaCommand(input string) bool {
+	return strings.HasPrefix(strings.TrimSpace(input), ":")
+}
+
+// splitMetaCommand splits ":name arg" into its name and argument.
+func splitMetaCommand(input string) (name, arg string) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), ":")
+	name, arg, _ = strings.Cut(input, " ")
+	return name, strings.TrimSpace(arg)
+}
+
+// needsMoreInput is IsIncomplete extended to meta-commands: only the
+// source argument of :tokens, :ast and :time may continue on the next line.
+func needsMoreInput(input string) bool {
+	if !isMetaCommand(input) {
+		return IsIncomplete(input)
+	}
+	name, arg := splitMetaCommand(input)
+	if cmd, ok := metaCommands[name]; ok && cmd.takesSource {
+		return IsIncomplete(arg)
+	}
+	return false
+}
+
+func (s *session) runMetaCommand(input string) {
+	name, arg := splitMetaCommand(input)
+	cmd, ok := metaCommands[name]
+	if !ok {
+		fmt.Fprintf(s.out, "unknown command :%s (try :help)\n", name)
+		return
+	}
+	cmd.run(s, arg)
+}
+
+func (s *session) printTokens(src string) {
+	l := lexer.New(src)
+	for tok := l.NextToken(); ; tok = l.NextToken() {
+		fmt.Fprintf(s.out, "%-7s %-10s %q\n", tok.Span.Start, tok.Type, tok.Literal)
+		if tok.Type == token.EOF {
+			return
+		}
+	}
+}
+
+func (s *session) printAST(src string) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Diagnostics()) != 0 {
+		printParserErrors(s.out, src, p.Diagnostics())
+	}
+	ast.Fprint(s.out, program)
+}
+
+func (s *session) printEnv(string) {
+	names := s.env.Names()
+	if len(names) == 0 {
+		fmt.Fprintln(s.out, "(no bindings)")
+		return
+	}
+	for _, name := range names {
+		val, _ := s.env.Get(name)
+		inspected := strings.ReplaceAll(val.Inspect(), "\n", " ")
+		fmt.Fprintf(s.out, "%s: %s = %s\n", name, val.Type(), inspected)
+	}
+}
+
+func (s *session) load(path string) {
+	if path == "" {
+		fmt.Fprintln(s.out, "usage: :load <file>")
+		return
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(s.out, err)
+		return
+	}
+	s.eval(string(src), path)
+}
+
+func (s *session) reset(string) {
+	s.env = s.newEnvironment()
+	fmt.Fprintln(s.out, "environment reset")
+}
+
+func (s *session) timeEval(src string) {
+	start := time.Now()
+	s.eval(src, "")
+	fmt.Fprintf(s.out, "took %s\n", time.Since(start))
+}
+
+func (s *session) printHelp(string) {
+	for _, name := range []string{"tokens", "ast", "env", "load", "reset", "time", "help"} {
+		cmd := metaCommands[name]
+		fmt.Fprintf(s.out, "  %-14s %s\n", cmd.usage, cmd.help)
+	}
+}
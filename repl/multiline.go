@@ -0,0 +1,73 @@
+package repl
+
+import (
+	"monkey/lexer"
+	"monkey/token"
+)
+
+// continuesOnNextLine lists tokens that cannot end a statement, so input
+// ending in one of them is waiting for more.
+var continuesOnNextLine = map[token.TokenType]bool{
+	token.ASSIGN:   true,
+	token.PLUS:     true,
+	token.MINUS:    true,
+	token.ASTERISK: true,
+	token.SLASH:    true,
+	token.PERCENT:  true,
+	token.BANG:     true,
+	token.LT:       true,
+	token.GT:       true,
+	token.LT_EQ:    true,
+	token.GT_EQ:    true,
+	token.AND:      true,
+	token.OR:       true,
+	token.BIT_AND:  true,
+	token.BIT_OR:   true,
+	token.BIT_XOR:  true,
+	token.BIT_NOT:  true,
+	token.SHL:      true,
+	token.SHR:      true,
+	token.EQ:       true,
+	token.NOT_EQ:   true,
+	token.COMMA:    true,
+	token.COLON:    true,
+	token.LET:      true,
+	token.RETURN:   true,
+	token.WHILE:    true,
+	token.FOR:      true,
+	token.IN:       true,
+	token.IF:       true,
+	token.ELSE:     true,
+	token.FUNCTION: true,
+}
+
+// IsIncomplete reports whether input is the start of a statement that
+// continues on the next line: it has unclosed brackets, ends in an operator
+// or keyword that needs an operand, or has an unterminated string or
+// block comment.
+// Input with too many closing brackets is complete, so the parser reports it.
+func IsIncomplete(input string) bool {
+	l := lexer.New(input)
+	depth := 0
+	last := token.Token{Type: token.EOF}
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		}
+		last = tok
+	}
+
+	for _, d := range l.Diagnostics() {
+		if d.Code == lexer.CodeUnterminatedString || d.Code == lexer.CodeUnterminatedComment {
+			return true
+		}
+	}
+	if depth != 0 {
+		return depth > 0
+	}
+	return continuesOnNextLine[last.Type]
+}
@@ -11,46 +11,146 @@
 package repl 
 
 import (
-	"bufio"
-	"fmt"
+	"errors"
 	"io"
+	"monkey/diagnostic"
 	"monkey/lexer"
 	"monkey/parser"
 	"monkey/evaluator"
+	"monkey/object"
+	"monkey/token"
+	"sort"
+	"strings"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT              = ">> "
+	CONTINUATION_PROMPT = ".. "
+)
 
+// Start runs the REPL until in is exhausted. When in and out are a terminal
+// the input line can be edited, with history and tab completion, and input
+// and results are syntax highlighted unless NO_COLOR is set.
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	s := &session{out: out, color: colorEnabled(out)}
+	s.env = s.newEnvironment()
+	reader := newLineReader(in, out, s.complete)
+	if term, ok := reader.(*terminalReader); ok && s.color {
+		term.editor.highlight = Highlight
+	}
 
 	for {
-		fmt.Fprintf(out, PROMPT)
-		scanned := scanner.Scan()
+		line, ok := readInput(reader)
 
-		if !scanned {
+		if !ok {
 			return
 		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
-
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		if isMetaCommand(line) {
+			s.runMetaCommand(line)
 			continue
 		}
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+		s.eval(line, "")
+	}
+}
+
+// session is the state kept between inputs of one REPL run.
+type session struct {
+	env   *object.Environment
+	out   io.Writer
+	color bool // print results with ANSI colors
+}
+
+// newEnvironment returns an empty environment whose puts prints to s.out.
+func (s *session) newEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	env.SetOutput(s.out)
+	return env
+}
+
+// eval parses and evaluates src in the session environment and prints
+// the result, or the syntax errors if src does not parse.
+func (s *session) eval(src string, filename string) {
+	l := lexer.NewWithFilename(filename, src)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Diagnostics()) != 0 {
+		printParserErrors(s.out, src, p.Diagnostics())
+		return
+	}
+	evaluated := evaluator.Eval(program, s.env)
+	if evaluated != nil {
+		io.WriteString(s.out, s.inspect(evaluated))
+		io.WriteString(s.out, "\n")
+	}
+}
+
+// inspect returns the printed form of a result, colored if enabled.
+func (s *session) inspect(obj object.Object) string {
+	if s.color {
+		return colorizeObject(obj)
+	}
+	return obj.Inspect()
+}
+
+// readInput reads lines until they form a complete statement, showing the
+// continuation prompt while more input is needed. Input cut short by EOF is
+// returned as is so its errors get reported; Ctrl-C discards the input.
+// It returns false at EOF.
+func readInput(reader lineReader) (string, bool) {
+	var lines []string
+	prompt := PROMPT
+	for {
+		line, err := reader.ReadLine(prompt)
+		if errors.Is(err, ErrInterrupted) {
+			return "", true
+		}
+		if err != nil {
+			break
 		}
+		lines = append(lines, line)
+		input := strings.Join(lines, "\n")
+		if !needsMoreInput(input) {
+			return input, true
+		}
+		prompt = CONTINUATION_PROMPT
+	}
+	if len(lines) > 0 {
+		return strings.Join(lines, "\n"), true
 	}
+	return "", false
 }
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
-	}	
+
+// complete returns the keywords, builtins and session bindings starting
+// with word, or the meta-commands if word starts with ':'.
+func (s *session) complete(word string) []string {
+	var names []string
+	if strings.HasPrefix(word, ":") {
+		for name := range metaCommands {
+			names = append(names, ":"+name)
+		}
+	} else {
+		names = append(names, token.Keywords()...)
+		names = append(names, evaluator.BuiltinNames()...)
+		names = append(names, s.env.Names()...)
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, name := range names {
+		if strings.HasPrefix(name, word) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+func printParserErrors(out io.Writer, source string, diags []diagnostic.Diagnostic) {
+	diagnostic.Render(out, source, diags)
 }
\ No newline at end of file
@@ -0,0 +1,309 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"5 + 5", false},
+		{"", false},
+		{"let add = fn(x, y) {", true},
+		{"let add = fn(x, y) {\n x + y\n}", false},
+		{"add(1,", true},
+		{"add(1,\n 2)", false},
+		{"[1, 2", true},
+		{`{"a": 1,`, true},
+		{"let x =", true},
+		{"1 +", true},
+		{"1 ==", true},
+		{"if (x) { 1 } else", true},
+		{"return", true},
+		{`"abc`, true},
+		{"\"abc\ndef\"", false},
+		{"1 + )", false},
+		{"}", false},
+		{"/* comment", true},
+		{"/* outer /* inner */", true},
+		{"/* comment */ 1", false},
+		{"1 // (", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsIncomplete(tt.input); got != tt.expected {
+			t.Errorf("IsIncomplete(%q) wrong. expected=%t, got=%t", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestStartMultiLine(t *testing.T) {
+	input := "let add = fn(a, b) {\n  a + b\n};\nadd(1,\n  2)\nlet s = \"x\n"
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := ">> .. .. >> .. 3\n>> .. "
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("REPL output wrong. expected prefix=%q, got=%q", expected, out.String())
+	}
+	if !strings.Contains(out.String(), "unterminated string literal") {
+		t.Errorf("expected error for input cut short by EOF, got=%q", out.String())
+	}
+}
+
+func TestMetaCommands(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "lib.monkey")
+	if err := os.WriteFile(script, []byte("let triple = fn(x) { x * 3 };\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{":tokens let x", []string{"1:1     LET        \"let\"\n", "1:5     IDENT      \"x\"\n", "EOF"}},
+		{":ast 1 +\n 2", []string{"InfixExpression \"+\" @1:3\n", "Right: IntegerLiteral \"2\" @2:2"}},
+		{"let a = 1;\n:env", []string{"a: INTEGER = 1\n"}},
+		{":load " + script + "\ntriple(2)", []string{"6\n"}},
+		{"let a = 1;\n:reset\n:env\na", []string{"environment reset\n", "(no bindings)\n", "identifier not found: a"}},
+		{":time 1 + 1", []string{"2\ntook "}},
+		{":reset\nputs(\"out\")", []string{"out\nnull\n"}},
+		{":load", []string{"usage: :load <file>"}},
+		{":nope", []string{"unknown command :nope"}},
+		{":help", []string{":tokens <code>", ":reset"}},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input+"\n"), &out)
+		for _, want := range tt.expected {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("input %q: expected output to contain %q, got=%q", tt.input, want, out.String())
+			}
+		}
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"let x = 1;\r", "let x = 1;"},
+		{"ab\x1b[D\x1b[DX\r", "Xab"},
+		{"abc\x01Y\x05Z\r", "YabcZ"},
+		{"abc\x7f\x7fd\r", "ad"},
+		{"abc\x1b[H\x1b[3~\r", "bc"},
+		{"abc\x1b[1~\x1b[4~!\r", "abc!"},
+		{"hello world\x17\r", "hello "},
+		{"abcdef\x1b[D\x1b[D\x0b\r", "abcd"},
+		{"abcdef\x1b[D\x1b[D\x15\r", "ef"},
+		{"héllo\x1b[D\x1b[D\x1b[D\x1b[D\x7f\r", "éllo"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := newLineEditor(strings.NewReader(tt.keys), &out, nil, nil)
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Errorf("keys %q: unexpected error: %v", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("keys %q: line wrong. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestLineEditorControlKeys(t *testing.T) {
+	var out bytes.Buffer
+	e := newLineEditor(strings.NewReader("abc\x03\x04"), &out, nil, nil)
+
+	if _, err := e.ReadLine(PROMPT); err != ErrInterrupted {
+		t.Errorf("Ctrl-C: expected ErrInterrupted, got=%v", err)
+	}
+	if _, err := e.ReadLine(PROMPT); err != io.EOF {
+		t.Errorf("Ctrl-D on empty line: expected io.EOF, got=%v", err)
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	up, down := "\x1b[A", "\x1b[B"
+	keys := "first\r" + "second\r" + "second\r" +
+		up + up + "!\r" + // recall "first"
+		"new" + up + down + "\r" // browsing keeps the pending line
+
+	var out bytes.Buffer
+	e := newLineEditor(strings.NewReader(keys), &out, nil, nil)
+
+	expected := []string{"first", "second", "second", "first!", "new"}
+	for _, want := range expected {
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if line != want {
+			t.Errorf("line wrong. expected=%q, got=%q", want, line)
+		}
+	}
+
+	if e.history.Len() != 4 {
+		t.Errorf("history should skip repeated lines. got=%d entries", e.history.Len())
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monkey", "history")
+
+	h := LoadHistory(path)
+	for _, line := range []string{"let a = 1;", "", "a + 1", "a + 1"} {
+		if err := h.Add(line); err != nil {
+			t.Fatalf("Add(%q) failed: %v", line, err)
+		}
+	}
+
+	loaded := LoadHistory(path)
+	if loaded.Len() != 2 {
+		t.Fatalf("loaded history has wrong length. got=%d", loaded.Len())
+	}
+	if loaded.At(0) != "let a = 1;" || loaded.At(1) != "a + 1" {
+		t.Errorf("loaded history wrong. got=%q, %q", loaded.At(0), loaded.At(1))
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	s := &session{env: object.NewEnvironment()}
+	s.env.Set("reduce", &object.Integer{Value: 1})
+	s.env.Set("result", &object.Integer{Value: 2})
+
+	tests := []struct {
+		word     string
+		expected []string
+	}{
+		{"re", []string{"reduce", "rest", "result", "return"}},
+		{"le", []string{"len", "let"}},
+		{"fn", []string{"fn"}},
+		{":lo", []string{":load"}},
+		{"zzz", nil},
+	}
+
+	for _, tt := range tests {
+		got := s.complete(tt.word)
+		if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("complete(%q) wrong. expected=%q, got=%q", tt.word, tt.expected, got)
+		}
+	}
+}
+
+func TestLineEditorCompletion(t *testing.T) {
+	s := &session{env: object.NewEnvironment()}
+	s.env.Set("counter", &object.Integer{Value: 1})
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"let y = cou\t\r", "let y = counter"},
+		{"put\t(1)\r", "puts(1)"},
+		{"pu\t\r", "pu"}, // ambiguous: push, puts
+		{"ret\t\r", "return"},
+		{"fi\t\r", "first"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := newLineEditor(strings.NewReader(tt.keys), &out, nil, s.complete)
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if line != tt.expected {
+			t.Errorf("keys %q: line wrong. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	input := `let s = "hi" + 10; é`
+	expected := "\x1b[35mlet\x1b[0m s \x1b[36m=\x1b[0m \x1b[32m\"hi\"\x1b[0m " +
+		"\x1b[36m+\x1b[0m \x1b[33m10\x1b[0m; \x1b[31mé\x1b[0m"
+
+	got := Highlight(input)
+	if got != expected {
+		t.Errorf("Highlight wrong.\nexpected=%q\ngot=     %q", expected, got)
+	}
+	if stripped := stripANSI(got); stripped != input {
+		t.Errorf("Highlight changed the text. expected=%q, got=%q", input, stripped)
+	}
+
+	if got := Highlight("x // note"); got != "x \x1b[2m// note\x1b[0m" {
+		t.Errorf("comment highlighted wrong. got=%q", got)
+	}
+
+	// a string still being typed is shown as a string, not as an error
+	if got := Highlight(`"abc`); got != "\x1b[32m\"abc\x1b[0m" {
+		t.Errorf("unterminated string highlighted wrong. got=%q", got)
+	}
+}
+
+func TestColorizeObject(t *testing.T) {
+	hash := &object.Hash{Pairs: map[object.HashKey]object.HashPair{}}
+	key := &object.String{Value: "a"}
+	hash.Pairs[key.HashKey()] = object.HashPair{Key: key, Value: &object.Boolean{Value: true}}
+
+	tests := []struct {
+		obj      object.Object
+		expected string
+	}{
+		{&object.Integer{Value: 5}, "\x1b[33m5\x1b[0m"},
+		{&object.Null{}, "\x1b[2mnull\x1b[0m"},
+		{&object.Error{Message: "boom"}, "\x1b[1;31mERROR: boom\x1b[0m"},
+		{&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.String{Value: "x"}}},
+			"[\x1b[33m1\x1b[0m, \x1b[32mx\x1b[0m]"},
+		{hash, "{\x1b[32ma\x1b[0m: \x1b[35mtrue\x1b[0m}"},
+	}
+
+	for _, tt := range tests {
+		got := colorizeObject(tt.obj)
+		if got != tt.expected {
+			t.Errorf("colorizeObject(%s) wrong. expected=%q, got=%q", tt.obj.Inspect(), tt.expected, got)
+		}
+		if stripANSI(got) != tt.obj.Inspect() {
+			t.Errorf("colorizeObject(%s) changed the text. got=%q", tt.obj.Inspect(), stripANSI(got))
+		}
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	if colorEnabled(&bytes.Buffer{}) {
+		t.Errorf("color should be disabled for output that is not a terminal")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if colorEnabled(os.Stdout) {
+		t.Errorf("color should be disabled when NO_COLOR is set")
+	}
+}
+
+func stripANSI(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package repl
+
+import "errors"
+
+// isTerminal always reports false outside Linux, so the REPL falls back
+// to reading plain lines.
+func isTerminal(fd uintptr) bool { return false }
+
+func makeRaw(fd uintptr) (restore func(), err error) {
+	return nil, errors.New("repl: raw terminal mode is only supported on Linux")
+}
@@ -1,10 +1,51 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType //  defined the TokenType type to be a string.  allows us to distinguish between different types of tokens
 	Literal string
+	Span    Span // where the token was found in the source
+}
+
+// Position is a location in the source code.
+// Line and Column are 1-based; Column counts characters, not bytes.
+// Offset is the 0-based byte offset into the input.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// IsValid reports whether the position carries a real location.
+// Tokens and nodes built by hand (e.g. in tests) have a zero Position.
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+// String returns the position in the form "file:line:column",
+// or "line:column" when there is no file name.
+func (p Position) String() string {
+	if !p.IsValid() {
+		if p.Filename != "" {
+			return p.Filename
+		}
+		return "-"
+	}
+	if p.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span is the half-open range [Start, End) covered by a token.
+type Span struct {
+	Start Position
+	End   Position
 }
 
 // we can define the possible TokenTypes as constants.
@@ -15,6 +56,10 @@ const (
 	// Identifiers + literals
 	IDENT TokenType = "IDENT" // add, foobar, x, y, ...
 	INT   TokenType = "INT"   // 1343456
+	FLOAT TokenType = "FLOAT" // 0.15, 1e-3
+
+	// Trivia, only produced when the lexer is asked to keep comments
+	COMMENT TokenType = "COMMENT" // "// ..." or "/* ... */"
 
 	// Operators
 	ASSIGN   TokenType = "="
@@ -23,17 +68,33 @@ const (
 	ASTERISK TokenType = "*"
 	SLASH    TokenType = "/"
 	BANG     TokenType = "!"
+	PERCENT  TokenType = "%"
 	LT       TokenType = "<"
 	GT       TokenType = ">"
+	LT_EQ    TokenType = "<="
+	GT_EQ    TokenType = ">="
+	AND      TokenType = "&&"
+	OR       TokenType = "||"
+
+	// Bitwise operators
+	BIT_AND TokenType = "&"
+	BIT_OR  TokenType = "|"
+	BIT_XOR TokenType = "^"
+	BIT_NOT TokenType = "~"
+	SHL     TokenType = "<<"
+	SHR     TokenType = ">>"
 
 	// Delimiters
 	COMMA     TokenType = ","
 	SEMICOLON TokenType = ";"
+	COLON     TokenType = ":"
 
 	LPAREN TokenType = "("
 	RPAREN TokenType = ")"
 	LBRACE TokenType = "{"
 	RBRACE TokenType = "}"
+	LBRACKET TokenType = "["
+	RBRACKET TokenType = "]"
 
 	// Keywords
 	FUNCTION TokenType = "FUNCTION"
@@ -41,6 +102,11 @@ const (
 	IF       TokenType = "IF"
 	ELSE     TokenType = "ELSE"
 	RETURN   TokenType = "RETURN"
+	WHILE    TokenType = "WHILE"
+	FOR      TokenType = "FOR"
+	IN       TokenType = "IN"
+	BREAK    TokenType = "BREAK"
+	CONTINUE TokenType = "CONTINUE"
 	TRUE     TokenType = "TRUE"
 	FALSE    TokenType = "FALSE"
 	EQ       = "=="
@@ -55,6 +121,11 @@ var keywords = map[string]TokenType{
 	"if": IF,
 	"else": ELSE,
 	"return": RETURN,
+	"while": WHILE,
+	"for": FOR,
+	"in": IN,
+	"break": BREAK,
+	"continue": CONTINUE,
 	"true": TRUE,
 	"false": FALSE,
 }
@@ -64,4 +135,14 @@ func LookupIdent(ident string) TokenType {
 		return tok
 	}
 	return IDENT
-}
\ No newline at end of file
+}
+
+// Keywords returns all reserved words in sorted order.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}